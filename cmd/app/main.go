@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"team-pharmacy/internal/auth"
 	"team-pharmacy/internal/config"
 	"team-pharmacy/internal/logger"
 	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/repository"
+	"team-pharmacy/internal/scheduler"
 	"team-pharmacy/internal/services"
 	"team-pharmacy/internal/transport"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -65,27 +73,211 @@ transport.RegisterRoutes(
 	reviewService,
 )
 
-	if err := db.AutoMigrate(&models.User{}, &models.Cart{}, &models.Medicine{}, &models.CartItem{}, &models.Order{}, &models.OrderItem{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Cart{},
+		&models.Medicine{},
+		&models.MedicineVariant{},
+		&models.Warehouse{},
+		&models.PickupPoint{},
+		&models.DeliveryZone{},
+		&models.DeliverySlot{},
+		&models.StockBatch{},
+		&models.StockTransfer{},
+		&models.StockTransferLine{},
+		&models.StockMovement{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderLine{},
+		&models.Stocktake{},
+		&models.StocktakeLine{},
+		&models.ActiveIngredient{},
+		&models.MedicineIngredient{},
+		&models.DrugInteraction{},
+		&models.CartItem{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OrderItemBatch{},
+		&models.Shipment{},
+		&models.DeliveryAttempt{},
+		&models.Payment{},
+		&models.Promocode{},
+		&models.PromocodeUsage{},
+		&models.Refund{},
+		&models.OrderEvent{},
+		&models.Recall{},
+		&models.RecallLot{},
+		&models.RecallNotification{},
+		&models.Prescription{},
+		&models.RefreshToken{},
+	); err != nil {
 		log.Fatalf("не удалось выполнить миграции: %v", err)
 	}
+	if err := repository.MigrateMedicineVariants(db); err != nil {
+		log.Fatalf("не удалось перенести остатки в варианты лекарств: %v", err)
+	}
+	if err := repository.MigrateStockBatches(db); err != nil {
+		log.Fatalf("не удалось перенести остатки в партии: %v", err)
+	}
+	if err := repository.MigrateWarehouses(db); err != nil {
+		log.Fatalf("не удалось перенести остатки в основной филиал: %v", err)
+	}
+	if err := repository.MigrateStockLedger(db); err != nil {
+		log.Fatalf("не удалось записать входящие остатки в журнал движений: %v", err)
+	}
+	if err := repository.MigrateMedicineSearch(db); err != nil {
+		log.Fatalf("не удалось подготовить поиск по каталогу: %v", err)
+	}
 	userRepo := repository.NewUserRepository(db)
 	cartRepo := repository.NewCartRepository(db, logger)
 	medicRepo := repository.NewMedicineRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	subCategory := repository.NewSubcategoryRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	promocodeRepo := repository.NewPromocodeRepository(db)
+	prescriptionRepo := repository.NewPrescriptionRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	ingredientRepo := repository.NewActiveIngredientRepository(db)
+	interactionRepo := repository.NewDrugInteractionRepository(db)
+	variantRepo := repository.NewMedicineVariantRepository(db)
+	batchRepo := repository.NewStockBatchRepository(db)
+	recallRepo := repository.NewRecallRepository(db)
+	warehouseRepo := repository.NewWarehouseRepository(db)
+	pickupPointRepo := repository.NewPickupPointRepository(db)
+	deliveryZoneRepo := repository.NewDeliveryZoneRepository(db)
+	deliverySlotRepo := repository.NewDeliverySlotRepository(db)
+	shipmentRepo := repository.NewShipmentRepository(db)
+	movementRepo := repository.NewStockMovementRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	stocktakeRepo := repository.NewStocktakeRepository(db)
+
+	var paymentProvider services.PaymentProvider
+	switch config.PaymentProvider() {
+	case "fake":
+		paymentProvider = services.NewFakePaymentProvider()
+	default:
+		log.Fatalf("платёжный провайдер %q не поддерживается", config.PaymentProvider())
+	}
+	authCfg := config.LoadAuthConfig()
+	tokenManager := auth.NewTokenManager(authCfg.JWTSecret, authCfg.AccessTokenTTL)
 
 	userService := services.NewUserService(userRepo)
-	cartService := services.NewCartService(cartRepo, userRepo, medicRepo, logger)
-	orderService := services.NewOrderService(orderRepo, userRepo, cartRepo, medicRepo)
+	cartService := services.NewCartService(cartRepo, userRepo, medicRepo, prescriptionRepo, logger)
+	promocodeService := services.NewPromocodeService(promocodeRepo)
+	interactionService := services.NewInteractionService(interactionRepo, ingredientRepo, medicRepo, cartRepo)
+	warehouseService := services.NewWarehouseService(warehouseRepo, medicRepo)
+	deliveryService := services.NewDeliveryService(deliveryZoneRepo, deliverySlotRepo)
+	orderService := services.NewOrderService(orderRepo, userRepo, cartRepo, medicRepo, prescriptionRepo,
+		promocodeService, paymentProvider, interactionService, services.InteractionPolicy(config.InteractionPolicy()),
+		warehouseService, pickupPointRepo, deliveryService, logger)
 	categoryService := services.NewCategoryService(categoryRepo)
 	subCategoryService := services.NewSubcategoryService(subCategory, categoryRepo)
+	medService := services.NewMedicineService(medicRepo, categoryRepo, subCategory, ingredientRepo, variantRepo)
+	paymentService := services.NewPaymentService(paymentRepo, orderRepo, paymentProvider)
+	prescriptionService := services.NewPrescriptionService(prescriptionRepo, userRepo, medicRepo)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, tokenManager, authCfg.RefreshTokenTTL)
+	stockService := services.NewStockService(batchRepo, variantRepo, medicRepo, warehouseRepo, movementRepo)
+	recallService := services.NewRecallService(recallRepo, medicRepo)
+	purchaseService := services.NewPurchaseService(supplierRepo, purchaseOrderRepo, variantRepo, warehouseRepo)
+
+	inventoryCfg := config.LoadInventoryConfig()
+	var notifier services.Notifier = services.NewLogNotifier(logger)
+	if inventoryCfg.NotifyWebhookURL != "" {
+		notifier = services.NewWebhookNotifier(inventoryCfg.NotifyWebhookURL)
+	}
+	inventoryService := services.NewInventoryService(inventoryRepo, notifier,
+		inventoryCfg.ReorderLookback, inventoryCfg.ReorderCoverage)
+	stocktakeService := services.NewStocktakeService(stocktakeRepo, warehouseRepo)
+	pickupPointService := services.NewPickupPointService(pickupPointRepo, warehouseRepo)
+	shipmentService := services.NewShipmentService(shipmentRepo, userRepo)
+
+	if authCfg.AdminEmail != "" && authCfg.AdminPassword != "" {
+		if err := authService.EnsureAdmin(authCfg.AdminEmail, authCfg.AdminPassword); err != nil {
+			log.Fatalf("не удалось создать администратора: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	schedulerCfg := config.LoadSchedulerConfig()
+	jobs := scheduler.New(logger)
+	jobs.Add(scheduler.Job{
+		Name:     "expire_unpaid_orders",
+		Interval: schedulerCfg.OrderExpirationInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := orderService.ExpireUnpaidOrders(schedulerCfg.PaymentWindow)
+			if expired > 0 {
+				logger.Info("unpaid orders expired", slog.Int("count", expired))
+			}
+			return err
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "retry_failed_refunds",
+		Interval: schedulerCfg.RefundRetryInterval,
+		Run: func(ctx context.Context) error {
+			retried, err := orderService.RetryFailedRefunds()
+			if retried > 0 {
+				logger.Info("failed refunds retried", slog.Int("count", retried))
+			}
+			return err
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "refresh_expired_stock",
+		Interval: schedulerCfg.StockExpiryInterval,
+		Run: func(ctx context.Context) error {
+			refreshed, err := stockService.RefreshExpiredStock()
+			if refreshed > 0 {
+				logger.Info("expired batches removed from stock", slog.Int("variants", refreshed))
+			}
+			return err
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "low_stock_alerts",
+		Interval: schedulerCfg.LowStockCheckInterval,
+		Run: func(ctx context.Context) error {
+			sent, err := inventoryService.CheckLowStock(ctx)
+			if sent > 0 {
+				logger.Info("low stock alerts sent", slog.Int("count", sent))
+			}
+			return err
+		},
+	})
+	jobs.Start(ctx)
 
 	router := gin.Default()
 
-	transport.RegisterRoutes(router, userService, cartService, orderService, categoryService, subCategoryService, logger)
+	transport.RegisterRoutes(router, userService, cartService, orderService, categoryService, subCategoryService, medService, paymentService,
+		promocodeService, prescriptionService, config.PrescriptionUploadDir(), authService, interactionService, stockService, recallService, warehouseService,
+		purchaseService, inventoryService, stocktakeService, pickupPointService,
+		deliveryService, shipmentService, logger)
+
+	serverAddr := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		serverAddr = ":" + port
+	}
+	server := &http.Server{Addr: serverAddr, Handler: router}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("не удалось запустить HTTP-сервер: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if err := router.Run(); err != nil {
-		log.Fatalf("не удалось запустить HTTP-сервер: %v", err)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to shutdown server", slog.Any("error", err))
 	}
+	jobs.Wait()
 }
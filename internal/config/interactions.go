@@ -0,0 +1,16 @@
+package config
+
+import "os"
+
+// InteractionPolicy - что делать при оформлении заказа с опасными
+// взаимодействиями лекарств: "warn" - вернуть предупреждение, "block" - отказать.
+func InteractionPolicy() string {
+	switch policy := os.Getenv("INTERACTION_POLICY"); policy {
+	case "warn", "block":
+		return policy
+	case "":
+		return "warn"
+	default:
+		panic("INTERACTION_POLICY must be warn or block")
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type InventoryConfig struct {
+	// ReorderLookback - за какой период брать продажи для расчёта рекомендуемого заказа.
+	ReorderLookback time.Duration
+	// ReorderCoverage - на сколько времени продаж должно хватить пополнения сверх точки заказа.
+	ReorderCoverage time.Duration
+	// NotifyWebhookURL - куда отправлять оповещения о низком остатке; если пусто, они пишутся в лог.
+	NotifyWebhookURL string
+}
+
+func LoadInventoryConfig() InventoryConfig {
+	return InventoryConfig{
+		ReorderLookback:  durationFromEnv("REORDER_LOOKBACK", 30*24*time.Hour),
+		ReorderCoverage:  durationFromEnv("REORDER_COVERAGE", 14*24*time.Hour),
+		NotifyWebhookURL: os.Getenv("NOTIFY_WEBHOOK_URL"),
+	}
+}
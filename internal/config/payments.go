@@ -0,0 +1,31 @@
+package config
+
+import "os"
+
+// PaymentProvider - платёжный шлюз для оплат и возвратов. Заглушка "fake" держит
+// платежи в памяти и теряет их при перезапуске (после этого не проходит ни один
+// возврат), поэтому допускается только при APP_ENV local или dev.
+func PaymentProvider() string {
+	provider := os.Getenv("PAYMENT_PROVIDER")
+	if provider == "" {
+		provider = "fake"
+	}
+
+	switch provider {
+	case "fake":
+		if env := AppEnv(); env != "local" && env != "dev" {
+			panic("PAYMENT_PROVIDER=fake is allowed only with APP_ENV local or dev")
+		}
+		return provider
+	default:
+		panic("unknown PAYMENT_PROVIDER " + provider)
+	}
+}
+
+// AppEnv - окружение запуска, по умолчанию local.
+func AppEnv() string {
+	if env := os.Getenv("APP_ENV"); env != "" {
+		return env
+	}
+	return "local"
+}
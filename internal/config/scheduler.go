@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type SchedulerConfig struct {
+	// PaymentWindow - сколько заказ может ждать оплату, прежде чем будет отменён.
+	PaymentWindow time.Duration
+	// OrderExpirationInterval - как часто проверять просроченные заказы.
+	OrderExpirationInterval time.Duration
+	// StockExpiryInterval - как часто убирать из остатков просроченные партии.
+	StockExpiryInterval time.Duration
+	// LowStockCheckInterval - как часто проверять остатки относительно точки заказа.
+	LowStockCheckInterval time.Duration
+	// RefundRetryInterval - как часто повторять возвраты, отклонённые провайдером.
+	RefundRetryInterval time.Duration
+}
+
+func LoadSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		PaymentWindow:           durationFromEnv("ORDER_PAYMENT_WINDOW", 30*time.Minute),
+		OrderExpirationInterval: durationFromEnv("ORDER_EXPIRATION_INTERVAL", time.Minute),
+		StockExpiryInterval:     durationFromEnv("STOCK_EXPIRY_INTERVAL", time.Hour),
+		LowStockCheckInterval:   durationFromEnv("LOW_STOCK_CHECK_INTERVAL", 15*time.Minute),
+		RefundRetryInterval:     durationFromEnv("REFUND_RETRY_INTERVAL", 10*time.Minute),
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
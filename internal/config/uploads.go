@@ -0,0 +1,11 @@
+package config
+
+import "os"
+
+// PrescriptionUploadDir - каталог, куда сохраняются загруженные сканы рецептов.
+func PrescriptionUploadDir() string {
+	if dir := os.Getenv("PRESCRIPTION_UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return "uploads/prescriptions"
+}
@@ -0,0 +1,31 @@
+package dto
+
+type RegisterRequest struct {
+	FullName       string `json:"full_name" binding:"required"`
+	Email          string `json:"email" binding:"required,email"`
+	Phone          string `json:"phone" binding:"required,min=11"`
+	DefaultAddress string `json:"default_address" binding:"required,max=255"`
+	Password       string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	All          bool   `json:"all"`
+}
+
+type TokenResponse struct {
+	UserID       uint   `json:"user_id"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
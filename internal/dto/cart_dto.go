@@ -2,13 +2,20 @@ package dto
 
 type AddCartItemRequest struct {
 	MedicineID uint `json:"medicine_id" binding:"required,gt=0"`
-	Quantity   int  `json:"quantity" binding:"required,gt=0"`
+	// VariantID можно не указывать, если у лекарства одна фасовка.
+	VariantID *uint `json:"variant_id" binding:"omitempty,gt=0"`
+	Quantity  int   `json:"quantity" binding:"required,gt=0"`
 }
 
 type UpdateCartItemRequest struct {
 	Quantity int `json:"quantity" binding:"required,gt=0"`
 }
 
+type SwapCartItemRequest struct {
+	MedicineID uint  `json:"medicine_id" binding:"required,gt=0"`
+	VariantID  *uint `json:"variant_id" binding:"omitempty,gt=0"`
+}
+
 type CartResponse struct {
 	UserID     uint               `json:"user_id"`
 	Items      []CartItemResponse `json:"items"`
@@ -16,9 +23,13 @@ type CartResponse struct {
 }
 
 type CartItemResponse struct {
-	ItemID       uint  `json:"item_id"`
-	MedicineID   uint  `json:"medicine_id"`
-	Quantity     int   `json:"quantity"`
-	PricePerUnit int64 `json:"price_per_unit"`
-	LineTotal    int64 `json:"line_total"`
+	ItemID       uint   `json:"item_id"`
+	MedicineID   uint   `json:"medicine_id"`
+	VariantID    uint   `json:"variant_id"`
+	SKU          string `json:"sku"`
+	Quantity     int    `json:"quantity"`
+	PricePerUnit int64  `json:"price_per_unit"`
+	LineTotal    int64  `json:"line_total"`
+	// RecallID - по фасовке объявлен отзыв серии, позицию стоит заменить.
+	RecallID *uint `json:"recall_id,omitempty"`
 }
@@ -0,0 +1,86 @@
+package dto
+
+import "time"
+
+type GeoPointInput struct {
+	Lat *float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lng *float64 `json:"lng" binding:"required,min=-180,max=180"`
+}
+
+// DeliveryZoneCreate - зона задаётся списком шестизначных индексов и/или
+// многоугольником не менее чем из трёх точек.
+type DeliveryZoneCreate struct {
+	Name             string          `json:"name" binding:"required,max=255"`
+	PostalCodes      []string        `json:"postal_codes" binding:"omitempty,dive,numeric,len=6"`
+	Polygon          []GeoPointInput `json:"polygon" binding:"omitempty,dive"`
+	Fee              int64           `json:"fee" binding:"min=0"`
+	MinOrderValue    int64           `json:"min_order_value" binding:"min=0"`
+	FreeDeliveryFrom *int64          `json:"free_delivery_from" binding:"omitempty,min=0"`
+	Priority         int             `json:"priority"`
+}
+
+// DeliveryZoneUpdate; postal_codes и polygon, если переданы, заменяются целиком,
+// clear_free_delivery делает доставку в зоне всегда платной.
+type DeliveryZoneUpdate struct {
+	Name              *string         `json:"name" binding:"omitempty,max=255"`
+	PostalCodes       []string        `json:"postal_codes" binding:"omitempty,dive,numeric,len=6"`
+	Polygon           []GeoPointInput `json:"polygon" binding:"omitempty,dive"`
+	Fee               *int64          `json:"fee" binding:"omitempty,min=0"`
+	MinOrderValue     *int64          `json:"min_order_value" binding:"omitempty,min=0"`
+	FreeDeliveryFrom  *int64          `json:"free_delivery_from" binding:"omitempty,min=0"`
+	ClearFreeDelivery bool            `json:"clear_free_delivery"`
+	Priority          *int            `json:"priority"`
+	IsActive          *bool           `json:"is_active"`
+}
+
+// DeliveryQuoteQuery - расчёт доставки до оформления заказа; amount - сумма товаров.
+type DeliveryQuoteQuery struct {
+	Address string   `form:"address" binding:"max=500"`
+	Lat     *float64 `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lng     *float64 `form:"lng" binding:"omitempty,min=-180,max=180"`
+	Amount  int64    `form:"amount" binding:"min=0"`
+}
+
+type DeliveryQuoteResponse struct {
+	ZoneID           uint   `json:"zone_id"`
+	ZoneName         string `json:"zone_name"`
+	Fee              int64  `json:"fee"`
+	MinOrderValue    int64  `json:"min_order_value"`
+	FreeDeliveryFrom *int64 `json:"free_delivery_from,omitempty"`
+	// BelowMinimum - с такой суммой заказ в зону не оформить.
+	BelowMinimum bool `json:"below_minimum"`
+}
+
+type DeliverySlotInput struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Capacity uint      `json:"capacity" binding:"required,min=1,max=10000"`
+}
+
+type DeliverySlotsCreate struct {
+	Slots []DeliverySlotInput `json:"slots" binding:"required,min=1,max=500,dive"`
+}
+
+// DeliverySlotUpdate; уменьшение вместимости ниже числа занятых мест не
+// отменяет заказы, а лишь закрывает запись на слот.
+type DeliverySlotUpdate struct {
+	Capacity *uint `json:"capacity" binding:"omitempty,min=1,max=10000"`
+	IsActive *bool `json:"is_active"`
+}
+
+// DeliverySlotQuery - слоты, начинающиеся в течение days дней с даты from
+// (по умолчанию - с текущего момента на 7 дней).
+type DeliverySlotQuery struct {
+	From string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	Days int    `form:"days" binding:"omitempty,min=1,max=31"`
+}
+
+type DeliverySlotResponse struct {
+	ID        uint      `json:"id"`
+	ZoneID    uint      `json:"zone_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Capacity  uint      `json:"capacity"`
+	Remaining uint      `json:"remaining"`
+	IsActive  bool      `json:"is_active"`
+}
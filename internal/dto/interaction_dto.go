@@ -0,0 +1,47 @@
+package dto
+
+import "team-pharmacy/internal/models"
+
+type InteractionImportItem struct {
+	INNA        string                     `json:"inn_a" binding:"required,max=150"`
+	INNB        string                     `json:"inn_b" binding:"required,max=150"`
+	Severity    models.InteractionSeverity `json:"severity" binding:"required,oneof=minor moderate severe"`
+	Description string                     `json:"description" binding:"required"`
+}
+
+type InteractionImportRequest struct {
+	Interactions []InteractionImportItem `json:"interactions" binding:"required,min=1,dive"`
+}
+
+type InteractionImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+type InteractionResponse struct {
+	ID          uint                       `json:"id"`
+	INNA        string                     `json:"inn_a"`
+	INNB        string                     `json:"inn_b"`
+	Severity    models.InteractionSeverity `json:"severity"`
+	Description string                     `json:"description"`
+}
+
+type InteractionMedicineResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// MedicineInteractionResponse - взаимодействие двух лекарств из корзины
+// через их действующие вещества.
+type MedicineInteractionResponse struct {
+	MedicineA   InteractionMedicineResponse `json:"medicine_a"`
+	MedicineB   InteractionMedicineResponse `json:"medicine_b"`
+	INNA        string                      `json:"inn_a"`
+	INNB        string                      `json:"inn_b"`
+	Severity    models.InteractionSeverity  `json:"severity"`
+	Description string                      `json:"description"`
+}
+
+type CartInteractionsResponse struct {
+	Interactions []MedicineInteractionResponse `json:"interactions"`
+	HasSevere    bool                          `json:"has_severe"`
+}
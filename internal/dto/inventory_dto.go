@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// LowStockItemResponse - строка отчёта о заканчивающихся лекарствах.
+type LowStockItemResponse struct {
+	MedicineID    uint   `json:"medicine_id"`
+	Name          string `json:"name"`
+	StockQuantity uint   `json:"stock_quantity"`
+	ReorderPoint  uint   `json:"reorder_point"`
+	// SoldQuantity - продано за последние LookbackDays дней.
+	SoldQuantity      int64      `json:"sold_quantity"`
+	LookbackDays      int        `json:"lookback_days"`
+	SuggestedQuantity uint       `json:"suggested_quantity"`
+	AlertedAt         *time.Time `json:"alerted_at,omitempty"`
+}
@@ -1,23 +1,121 @@
 package dto
 
+import "team-pharmacy/internal/models"
+
 type MedicineCreate struct {
-	Name                 string  `json:"name" binding:"required"`
-	Description          string  `json:"description"`
-	Price                uint64 `json:"price" binding:"required,min=0.01,max=999999999"`
-	StockQuantity        uint    `json:"stock_quantity" binding:"required"`
-	CategoryID           *uint   `json:"category_id" binding:"required"`
-	SubcategoryID        *uint   `json:"subcategory_id" binding:"required"`
-	Manufacturer         string  `json:"manufacturer" binding:"required"`
-	PrescriptionRequired bool    `json:"prescription_required" binding:"required"`
+	Name                 string                    `json:"name" binding:"required"`
+	Description          string                    `json:"description"`
+	Price                uint64                    `json:"price" binding:"required_without=Variants,max=999999999"`
+	CategoryID           *uint                     `json:"category_id" binding:"required"`
+	SubcategoryID        *uint                     `json:"subcategory_id" binding:"required"`
+	Manufacturer         string                    `json:"manufacturer" binding:"required"`
+	PrescriptionRequired bool                      `json:"prescription_required" binding:"required"`
+	DosageForm           models.DosageForm         `json:"dosage_form" binding:"omitempty,oneof=tablet capsule syrup suspension solution injection drops spray ointment cream gel powder suppository"`
+	Ingredients          []MedicineIngredientInput `json:"ingredients" binding:"omitempty,dive"`
+	ReorderPoint         uint                      `json:"reorder_point"`
+	// Variants - фасовки; если не заданы, создаётся одна с ценой Price.
+	// Остаток появляется только при поступлении партий.
+	Variants []MedicineVariantInput `json:"variants" binding:"omitempty,dive"`
 }
 
 type MedicineUpdate struct {
-	Name                 *string  `json:"name" binding:"omitempty"`
-	Description          *string  `json:"description"`
-	Price                *uint64 `json:"price" binding:"omitempty,min=0.01,max=999999999"`
-	StockQuantity        *uint    `json:"stock_quantity"`
-	CategoryID           *uint    `json:"category_id" binding:"omitempty"`
-	SubcategoryID        *uint    `json:"subcategory_id" binding:"omitempty"`
-	Manufacturer         *string  `json:"manufacturer" binding:"omitempty"`
-	PrescriptionRequired *bool    `json:"prescription_required" binding:"omitempty"`
+	Name                 *string            `json:"name" binding:"omitempty"`
+	Description          *string            `json:"description"`
+	Price                *uint64            `json:"price" binding:"omitempty,min=0.01,max=999999999"`
+	CategoryID           *uint              `json:"category_id" binding:"omitempty"`
+	SubcategoryID        *uint              `json:"subcategory_id" binding:"omitempty"`
+	Manufacturer         *string            `json:"manufacturer" binding:"omitempty"`
+	PrescriptionRequired *bool              `json:"prescription_required" binding:"omitempty"`
+	DosageForm           *models.DosageForm `json:"dosage_form" binding:"omitempty,oneof=tablet capsule syrup suspension solution injection drops spray ointment cream gel powder suppository"`
+	ReorderPoint         *uint              `json:"reorder_point"`
+	// Ingredients заменяет состав целиком; пустой список очищает его.
+	Ingredients []MedicineIngredientInput `json:"ingredients" binding:"omitempty,dive"`
+}
+
+type MedicineVariantInput struct {
+	SKU     string  `json:"sku" binding:"omitempty,max=64"`
+	Name    string  `json:"name" binding:"required,max=100"`
+	Barcode *string `json:"barcode" binding:"omitempty,max=32"`
+	Price   uint64  `json:"price" binding:"required,max=999999999"`
+}
+
+type MedicineVariantUpdate struct {
+	SKU     *string `json:"sku" binding:"omitempty,max=64"`
+	Name    *string `json:"name" binding:"omitempty,max=100"`
+	Barcode *string `json:"barcode" binding:"omitempty,max=32"`
+	Price   *uint64 `json:"price" binding:"omitempty,min=1,max=999999999"`
+}
+
+type MedicineIngredientInput struct {
+	INN          string  `json:"inn" binding:"required,max=150"`
+	Strength     float64 `json:"strength" binding:"required,gt=0"`
+	StrengthUnit string  `json:"strength_unit" binding:"required,max=16"`
+}
+
+// MedicineListQuery - параметры GET /medicines. Для постраничного вывода
+// используется либо offset, либо cursor из next_cursor предыдущего ответа.
+type MedicineListQuery struct {
+	Query                string   `form:"q"`
+	CategoryID           *uint    `form:"category_id"`
+	SubcategoryID        *uint    `form:"subcategory_id"`
+	Manufacturer         string   `form:"manufacturer"`
+	PriceMin             *uint64  `form:"price_min"`
+	PriceMax             *uint64  `form:"price_max"`
+	InStock              *bool    `form:"in_stock"`
+	PrescriptionRequired *bool    `form:"prescription_required"`
+	MinRating            *float64 `form:"min_rating" binding:"omitempty,min=0,max=10"`
+	WarehouseID          *uint    `form:"warehouse_id"`
+	Sort                 string   `form:"sort" binding:"omitempty,oneof=newest price_asc price_desc rating name"`
+	Limit                int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset               int      `form:"offset" binding:"omitempty,min=0"`
+	Cursor               string   `form:"cursor"`
+}
+
+type CategoryFacetResponse struct {
+	CategoryID uint   `json:"category_id"`
+	Name       string `json:"name"`
+	Count      int64  `json:"count"`
+}
+
+type ManufacturerFacetResponse struct {
+	Manufacturer string `json:"manufacturer"`
+	Count        int64  `json:"count"`
+}
+
+type MedicineFacetsResponse struct {
+	Categories    []CategoryFacetResponse     `json:"categories"`
+	Manufacturers []ManufacturerFacetResponse `json:"manufacturers"`
+}
+
+type MedicineListResponse struct {
+	Items      []models.Medicine      `json:"items"`
+	Total      int64                  `json:"total"`
+	Limit      int                    `json:"limit"`
+	Offset     int                    `json:"offset,omitempty"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	Facets     MedicineFacetsResponse `json:"facets"`
+}
+
+type MedicineSearchQuery struct {
+	Query  string `form:"q" binding:"required"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type MedicineSearchHit struct {
+	Medicine models.Medicine `json:"medicine"`
+	Rank     float64         `json:"rank"`
+	// Name и Snippet содержат совпадения, выделенные тегом <mark>.
+	Name    string `json:"name"`
+	Snippet string `json:"snippet"`
+}
+
+type MedicineSearchResponse struct {
+	// Mode - "fulltext" или "fuzzy", если точных совпадений не нашлось
+	// и результат подобран по похожему написанию.
+	Mode   string              `json:"mode"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+	Items  []MedicineSearchHit `json:"items"`
 }
@@ -6,9 +6,18 @@ import (
 )
 
 type OrderCreateRequest struct {
-	DeliveryAddress string `json:"delivery_address" binding:"required"`
-	Comment         string `json:"comment"`
-	Promocode       string `json:"promocode"`
+	// FulfilmentMode - delivery (по умолчанию) или pickup.
+	FulfilmentMode  models.FulfilmentMode `json:"fulfilment_mode" binding:"omitempty,oneof=delivery pickup"`
+	DeliveryAddress string                `json:"delivery_address" binding:"required_unless=FulfilmentMode pickup"`
+	// DeliveryLat и DeliveryLng - точка на карте; если не переданы обе, зона ищется по индексу в адресе.
+	DeliveryLat *float64 `json:"delivery_lat" binding:"omitempty,min=-90,max=90"`
+	DeliveryLng *float64 `json:"delivery_lng" binding:"omitempty,min=-180,max=180"`
+	// DeliverySlotID - интервал доставки из слотов зоны адреса, необязателен.
+	DeliverySlotID *uint `json:"delivery_slot_id"`
+	// PickupPointID - пункт самовывоза; заказ собирается в его филиале.
+	PickupPointID *uint  `json:"pickup_point_id" binding:"required_if=FulfilmentMode pickup"`
+	Comment       string `json:"comment"`
+	Promocode     string `json:"promocode"`
 }
 
 type OrderShortResponse struct {
@@ -19,25 +28,71 @@ type OrderShortResponse struct {
 
 type OrderStatusRequest struct {
 	Status *models.OrderStatus `json:"status" binding:"required"`
+	Reason string              `json:"reason" binding:"max=255"`
+}
+
+type OrderCancelRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
 }
 
 type OrderResponse struct {
-	UserID          uint                `json:"user_id"`
-	Status          string              `json:"status"`
-	TotalPrice      int64               `json:"total_price"`
-	DiscountTotal   int64               `json:"discount_total"`
-	FinalPrice      int64               `json:"final_price"`
-	DeliveryAddress string              `json:"delivery_address"`
-	Comment         string              `json:"comment"`
-	Items           []OrderItemResponse `json:"items"`
-	CreatedAt       time.Time           `json:"created_at"`
-	// Payments        []models.Payment   `gorm:"constraint:OnDelete:CASCADE;"`
+	UserID          uint                       `json:"user_id"`
+	Status          string                     `json:"status"`
+	TotalPrice      int64                      `json:"total_price"`
+	DiscountTotal   int64                      `json:"discount_total"`
+	DeliveryFee     int64                      `json:"delivery_fee"`
+	DeliveryZoneID  *uint                      `json:"delivery_zone_id,omitempty"`
+	DeliverySlot    *OrderDeliverySlotResponse `json:"delivery_slot,omitempty"`
+	Shipment        *ShipmentResponse          `json:"shipment,omitempty"`
+	FinalPrice      int64                      `json:"final_price"`
+	FulfilmentMode  models.FulfilmentMode      `json:"fulfilment_mode"`
+	DeliveryAddress string                     `json:"delivery_address,omitempty"`
+	PickupPointID   *uint                      `json:"pickup_point_id,omitempty"`
+	// PickupCode показывается только покупателю.
+	PickupCode   string               `json:"pickup_code,omitempty"`
+	Comment      string               `json:"comment"`
+	Items        []OrderItemResponse  `json:"items"`
+	CreatedAt    time.Time            `json:"created_at"`
+	Payments     []PaymentResponse    `json:"payments,omitempty"`
+	Refunds      []RefundResponse     `json:"refunds,omitempty"`
+	CanceledAt   *time.Time           `json:"canceled_at,omitempty"`
+	CancelReason string               `json:"cancel_reason,omitempty"`
+	RecallID     *uint                `json:"recall_id,omitempty"`
+	WarehouseID  *uint                `json:"warehouse_id,omitempty"`
+	History      []OrderEventResponse `json:"history"`
+	// InteractionWarnings заполняется только в ответе на создание заказа.
+	InteractionWarnings []MedicineInteractionResponse `json:"interaction_warnings,omitempty"`
+}
+
+type OrderEventResponse struct {
+	FromStatus models.OrderStatus `json:"from_status,omitempty"`
+	ToStatus   models.OrderStatus `json:"to_status"`
+	ActorID    *uint              `json:"actor_id,omitempty"`
+	Reason     string             `json:"reason,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
 }
 
 type OrderItemResponse struct {
 	MedicineID   uint   `json:"medicine_id"`
 	MedicineName string `json:"medicine_name"`
+	VariantID    *uint  `json:"variant_id,omitempty"`
+	VariantName  string `json:"variant_name,omitempty"`
+	SKU          string `json:"sku,omitempty"`
 	Quantity     int    `json:"quantity"`
 	PricePerUnit int64  `json:"price_per_unit"`
 	LineTotal    int64  `json:"line_total"`
+	// Batches - партии, с которых списан товар (FEFO).
+	Batches []OrderItemBatchResponse `json:"batches,omitempty"`
+}
+
+type OrderItemBatchResponse struct {
+	LotNumber string     `json:"lot_number"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Quantity  int        `json:"quantity"`
+}
+
+type OrderDeliverySlotResponse struct {
+	ID       uint      `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
 }
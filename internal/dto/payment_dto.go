@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+type PaymentCreateRequest struct {
+	Method string `json:"method" binding:"required,oneof=card sbp cash"`
+}
+
+type PaymentFailRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+type PaymentResponse struct {
+	ID            uint          `json:"id"`
+	OrderID       uint          `json:"order_id"`
+	Amount        int64         `json:"amount"`
+	Status        models.Status `json:"status"`
+	Method        string        `json:"method"`
+	Provider      string        `json:"provider"`
+	ExternalID    string        `json:"external_id"`
+	FailureReason string        `json:"failure_reason,omitempty"`
+	PaidAt        *time.Time    `json:"paid_at,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type RefundResponse struct {
+	ID        uint          `json:"id"`
+	PaymentID uint          `json:"payment_id"`
+	Amount    int64         `json:"amount"`
+	Status    models.Status `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+}
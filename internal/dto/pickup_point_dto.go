@@ -0,0 +1,40 @@
+package dto
+
+// OpeningHoursInput - часы работы в день недели (0 - воскресенье), время HH:MM.
+type OpeningHoursInput struct {
+	Weekday *int   `json:"weekday" binding:"required,min=0,max=6"`
+	Opens   string `json:"opens" binding:"required,datetime=15:04"`
+	Closes  string `json:"closes" binding:"required,datetime=15:04"`
+}
+
+type PickupPointCreate struct {
+	WarehouseID  uint                `json:"warehouse_id" binding:"required"`
+	Name         string              `json:"name" binding:"required,max=255"`
+	City         string              `json:"city" binding:"required,max=100"`
+	Address      string              `json:"address" binding:"required,max=255"`
+	Phone        string              `json:"phone" binding:"omitempty,max=20"`
+	OpeningHours []OpeningHoursInput `json:"opening_hours" binding:"required,min=1,dive"`
+}
+
+// PickupPointUpdate; opening_hours, если переданы, заменяют расписание целиком.
+type PickupPointUpdate struct {
+	WarehouseID  *uint               `json:"warehouse_id"`
+	Name         *string             `json:"name" binding:"omitempty,max=255"`
+	City         *string             `json:"city" binding:"omitempty,max=100"`
+	Address      *string             `json:"address" binding:"omitempty,max=255"`
+	Phone        *string             `json:"phone" binding:"omitempty,max=20"`
+	OpeningHours []OpeningHoursInput `json:"opening_hours" binding:"omitempty,min=1,dive"`
+	IsActive     *bool               `json:"is_active"`
+}
+
+type PickupPointQuery struct {
+	City            string `form:"city"`
+	IncludeInactive bool   `form:"include_inactive"`
+	// OpenNow оставляет только пункты, работающие в момент запроса.
+	OpenNow bool `form:"open_now"`
+}
+
+// OrderPickupRequest - код получения, который покупатель называет в пункте выдачи.
+type OrderPickupRequest struct {
+	Code string `json:"code" binding:"required,max=8"`
+}
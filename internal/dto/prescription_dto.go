@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type PrescriptionUploadRequest struct {
+	Code        string `form:"code" binding:"max=64"`
+	MedicineIDs []uint `form:"medicine_ids" binding:"required,min=1,dive,gt=0"`
+}
+
+type PrescriptionReviewRequest struct {
+	Comment    string     `json:"comment" binding:"max=255"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+type PromocodeCreate struct {
+	Code          string              `json:"code" binding:"required,max=64"`
+	DiscountType  models.DiscountType `json:"discount_type" binding:"required,oneof=percent fixed"`
+	Value         int64               `json:"value" binding:"required,gt=0"`
+	MinOrderTotal int64               `json:"min_order_total" binding:"omitempty,gte=0"`
+	ValidFrom     *time.Time          `json:"valid_from"`
+	ValidUntil    *time.Time          `json:"valid_until"`
+	UsageLimit    int                 `json:"usage_limit" binding:"omitempty,gte=0"`
+	PerUserLimit  int                 `json:"per_user_limit" binding:"omitempty,gte=0"`
+	CategoryID    *uint               `json:"category_id"`
+	MedicineID    *uint               `json:"medicine_id"`
+}
+
+type PromocodeUpdate struct {
+	DiscountType  *models.DiscountType `json:"discount_type" binding:"omitempty,oneof=percent fixed"`
+	Value         *int64               `json:"value" binding:"omitempty,gt=0"`
+	MinOrderTotal *int64               `json:"min_order_total" binding:"omitempty,gte=0"`
+	ValidFrom     *time.Time           `json:"valid_from"`
+	ValidUntil    *time.Time           `json:"valid_until"`
+	UsageLimit    *int                 `json:"usage_limit" binding:"omitempty,gte=0"`
+	PerUserLimit  *int                 `json:"per_user_limit" binding:"omitempty,gte=0"`
+	IsActive      *bool                `json:"is_active"`
+	CategoryID    *uint                `json:"category_id"`
+	MedicineID    *uint                `json:"medicine_id"`
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+type SupplierCreate struct {
+	Name    string  `json:"name" binding:"required,max=255"`
+	TaxID   *string `json:"tax_id" binding:"omitempty,max=20"`
+	Email   string  `json:"email" binding:"omitempty,email,max=255"`
+	Phone   string  `json:"phone" binding:"omitempty,max=20"`
+	Address string  `json:"address" binding:"omitempty,max=255"`
+}
+
+type SupplierUpdate struct {
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	TaxID    *string `json:"tax_id" binding:"omitempty,max=20"`
+	Email    *string `json:"email" binding:"omitempty,email,max=255"`
+	Phone    *string `json:"phone" binding:"omitempty,max=20"`
+	Address  *string `json:"address" binding:"omitempty,max=255"`
+	IsActive *bool   `json:"is_active"`
+}
+
+type PurchaseOrderLineInput struct {
+	VariantID uint   `json:"variant_id" binding:"required"`
+	Quantity  uint   `json:"quantity" binding:"required,min=1,max=1000000"`
+	UnitCost  uint64 `json:"unit_cost" binding:"max=999999999"`
+}
+
+// PurchaseOrderCreate - черновик заказа поставщику; expected_at в формате YYYY-MM-DD.
+type PurchaseOrderCreate struct {
+	SupplierID  uint                     `json:"supplier_id" binding:"required"`
+	WarehouseID uint                     `json:"warehouse_id" binding:"required"`
+	ExpectedAt  *string                  `json:"expected_at" binding:"omitempty,datetime=2006-01-02"`
+	Comment     string                   `json:"comment" binding:"max=255"`
+	Lines       []PurchaseOrderLineInput `json:"lines" binding:"required,min=1,dive"`
+}
+
+// PurchaseOrderUpdate меняет черновик; lines, если переданы, заменяют строки целиком.
+type PurchaseOrderUpdate struct {
+	ExpectedAt *string                  `json:"expected_at" binding:"omitempty,datetime=2006-01-02"`
+	Comment    *string                  `json:"comment" binding:"omitempty,max=255"`
+	Lines      []PurchaseOrderLineInput `json:"lines" binding:"omitempty,min=1,dive"`
+}
+
+type PurchaseReceiveLine struct {
+	LineID    uint   `json:"line_id" binding:"required"`
+	LotNumber string `json:"lot_number" binding:"required,max=64"`
+	ExpiresAt string `json:"expires_at" binding:"required,datetime=2006-01-02"`
+	Quantity  uint   `json:"quantity" binding:"required,min=1"`
+}
+
+type PurchaseReceiveRequest struct {
+	Lines []PurchaseReceiveLine `json:"lines" binding:"required,min=1,dive"`
+}
+
+type PurchaseOrderQuery struct {
+	Status      string `form:"status" binding:"omitempty,oneof=draft sent partially_received received"`
+	SupplierID  *uint  `form:"supplier_id"`
+	WarehouseID *uint  `form:"warehouse_id"`
+}
+
+type PurchaseOrderLineResponse struct {
+	ID               uint   `json:"id"`
+	VariantID        uint   `json:"variant_id"`
+	MedicineID       uint   `json:"medicine_id"`
+	Quantity         uint   `json:"quantity"`
+	ReceivedQuantity uint   `json:"received_quantity"`
+	UnitCost         uint64 `json:"unit_cost"`
+	LineTotal        uint64 `json:"line_total"`
+}
+
+type PurchaseOrderResponse struct {
+	ID           uint                        `json:"id"`
+	SupplierID   uint                        `json:"supplier_id"`
+	SupplierName string                      `json:"supplier_name,omitempty"`
+	WarehouseID  uint                        `json:"warehouse_id"`
+	Status       models.PurchaseOrderStatus  `json:"status"`
+	ExpectedAt   *time.Time                  `json:"expected_at,omitempty"`
+	Comment      string                      `json:"comment,omitempty"`
+	TotalCost    uint64                      `json:"total_cost"`
+	CreatedBy    *uint                       `json:"created_by,omitempty"`
+	CreatedAt    time.Time                   `json:"created_at"`
+	SentAt       *time.Time                  `json:"sent_at,omitempty"`
+	ReceivedAt   *time.Time                  `json:"received_at,omitempty"`
+	Lines        []PurchaseOrderLineResponse `json:"lines"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+type RecallCreateRequest struct {
+	MedicineID uint     `json:"medicine_id" binding:"required"`
+	LotNumbers []string `json:"lot_numbers" binding:"required,min=1,dive,required,max=64"`
+	Reason     string   `json:"reason" binding:"required,max=2000"`
+}
+
+type RecallResponse struct {
+	ID           uint      `json:"id"`
+	MedicineID   uint      `json:"medicine_id"`
+	MedicineName string    `json:"medicine_name"`
+	LotNumbers   []string  `json:"lot_numbers"`
+	Reason       string    `json:"reason"`
+	CreatedBy    *uint     `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Customers - список оповещения; заполняется в ответе на создание и при просмотре отзыва.
+	Customers []RecallCustomerResponse `json:"customers,omitempty"`
+}
+
+// RecallCustomerResponse - покупатель, которого нужно оповестить об отзыве.
+type RecallCustomerResponse struct {
+	UserID   uint                  `json:"user_id"`
+	FullName string                `json:"full_name"`
+	Email    string                `json:"email"`
+	Phone    string                `json:"phone"`
+	Orders   []RecallOrderResponse `json:"orders,omitempty"`
+	InCart   bool                  `json:"in_cart"`
+}
+
+type RecallOrderResponse struct {
+	OrderID uint               `json:"order_id"`
+	Status  models.OrderStatus `json:"status"`
+	// Flagged - заказ не был отгружен и помечен отзывом.
+	Flagged bool `json:"flagged"`
+}
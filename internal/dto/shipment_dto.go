@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+// ShipmentCreate - отправление своим курьером (courier_id) или службой
+// доставки (carrier и tracking_number).
+type ShipmentCreate struct {
+	CourierID      *uint   `json:"courier_id"`
+	Carrier        string  `json:"carrier" binding:"max=64"`
+	TrackingNumber *string `json:"tracking_number" binding:"omitempty,max=64"`
+}
+
+type ShipmentUpdate struct {
+	CourierID      *uint   `json:"courier_id"`
+	Carrier        *string `json:"carrier" binding:"omitempty,max=64"`
+	TrackingNumber *string `json:"tracking_number" binding:"omitempty,max=64"`
+}
+
+// DeliveryAttemptRequest - результат попытки вручения; для неудачной нужна причина.
+type DeliveryAttemptRequest struct {
+	Outcome models.DeliveryAttemptOutcome `json:"outcome" binding:"required,oneof=delivered failed"`
+	Comment string                        `json:"comment" binding:"required_if=Outcome failed,max=255"`
+}
+
+type CourierShipmentQuery struct {
+	// All - вместе с уже вручёнными отправлениями.
+	All bool `form:"all"`
+}
+
+type DeliveryAttemptResponse struct {
+	ID        uint                          `json:"id"`
+	CourierID *uint                         `json:"courier_id,omitempty"`
+	Outcome   models.DeliveryAttemptOutcome `json:"outcome"`
+	Comment   string                        `json:"comment,omitempty"`
+	CreatedAt time.Time                     `json:"created_at"`
+}
+
+type ShipmentResponse struct {
+	ID             uint                  `json:"id"`
+	OrderID        uint                  `json:"order_id"`
+	CourierID      *uint                 `json:"courier_id,omitempty"`
+	Carrier        string                `json:"carrier,omitempty"`
+	TrackingNumber *string               `json:"tracking_number,omitempty"`
+	Status         models.ShipmentStatus `json:"status"`
+	// DeliveryAddress заполняется в списке отправлений курьера.
+	DeliveryAddress string                    `json:"delivery_address,omitempty"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	ShippedAt       *time.Time                `json:"shipped_at,omitempty"`
+	DeliveredAt     *time.Time                `json:"delivered_at,omitempty"`
+	Attempts        []DeliveryAttemptResponse `json:"attempts,omitempty"`
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+// StockBatchCreateRequest - поступление партии; expires_at в формате YYYY-MM-DD.
+type StockBatchCreateRequest struct {
+	WarehouseID uint   `json:"warehouse_id" binding:"required"`
+	LotNumber   string `json:"lot_number" binding:"required,max=64"`
+	ExpiresAt   string `json:"expires_at" binding:"required,datetime=2006-01-02"`
+	Quantity    uint   `json:"quantity" binding:"required,min=1,max=1000000"`
+}
+
+type StockBatchResponse struct {
+	ID               uint       `json:"id"`
+	VariantID        uint       `json:"variant_id"`
+	MedicineID       uint       `json:"medicine_id"`
+	WarehouseID      uint       `json:"warehouse_id"`
+	LotNumber        string     `json:"lot_number"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	Quantity         uint       `json:"quantity"`
+	ReceivedQuantity uint       `json:"received_quantity"`
+	Expired          bool       `json:"expired"`
+	RecallID         *uint      `json:"recall_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// StockMovementQuery - параметры GET /medicines/:id/stock-movements; from и to в формате YYYY-MM-DD,
+// to не включается.
+type StockMovementQuery struct {
+	VariantID   *uint      `form:"variant_id"`
+	WarehouseID *uint      `form:"warehouse_id"`
+	Type        string     `form:"type" binding:"omitempty,oneof=receipt sale return write_off adjustment transfer"`
+	From        *time.Time `form:"from" time_format:"2006-01-02"`
+	To          *time.Time `form:"to" time_format:"2006-01-02"`
+	Limit       int        `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset      int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// StockMovementCreateRequest - ручное движение по партии. Для write_off quantity -
+// сколько списать, для adjustment - изменение остатка со знаком.
+type StockMovementCreateRequest struct {
+	Type     models.StockMovementType `json:"type" binding:"required,oneof=write_off adjustment"`
+	Quantity int                      `json:"quantity" binding:"required,ne=0"`
+	Reason   string                   `json:"reason" binding:"required,max=255"`
+}
+
+type StockMovementResponse struct {
+	ID          uint                     `json:"id"`
+	CreatedAt   time.Time                `json:"created_at"`
+	BatchID     uint                     `json:"batch_id"`
+	VariantID   uint                     `json:"variant_id"`
+	WarehouseID uint                     `json:"warehouse_id"`
+	Type        models.StockMovementType `json:"type"`
+	Delta       int                      `json:"delta"`
+	// Balance - остаток в рамках фильтра после движения; заполняется в списке.
+	Balance      *int   `json:"balance,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	ActorID      *uint  `json:"actor_id,omitempty"`
+	OrderID      *uint  `json:"order_id,omitempty"`
+	DocumentType string `json:"document_type,omitempty"`
+	DocumentID   *uint  `json:"document_id,omitempty"`
+}
+
+type StockMovementListResponse struct {
+	Items []StockMovementResponse `json:"items"`
+	Total int64                   `json:"total"`
+	// Balance - текущий остаток по журналу с учётом variant_id и warehouse_id.
+	Balance int64 `json:"balance"`
+	Limit   int   `json:"limit"`
+	Offset  int   `json:"offset"`
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"team-pharmacy/internal/models"
+	"time"
+)
+
+// StocktakeCreate открывает инвентаризацию филиала; без medicine_ids
+// пересчитываются все партии филиала.
+type StocktakeCreate struct {
+	WarehouseID uint   `json:"warehouse_id" binding:"required"`
+	MedicineIDs []uint `json:"medicine_ids" binding:"omitempty,dive,required"`
+	Comment     string `json:"comment" binding:"max=255"`
+}
+
+// StocktakeCountInput - фактическое количество партии (batch_id), фасовки целиком
+// (variant_id) или лекарства (medicine_id, только если в инвентаризации одна его
+// фасовка: разные фасовки нельзя сложить в одно число). Общее количество
+// раскладывается по продаваемым партиям: недостача относится к партиям с ближайшим
+// сроком годности. Отозванные и просроченные партии считаются только по batch_id.
+type StocktakeCountInput struct {
+	BatchID         *uint `json:"batch_id"`
+	VariantID       *uint `json:"variant_id"`
+	MedicineID      *uint `json:"medicine_id"`
+	CountedQuantity *int  `json:"counted_quantity" binding:"required,min=0,max=1000000"`
+}
+
+type StocktakeCountRequest struct {
+	Counts []StocktakeCountInput `json:"counts" binding:"required,min=1,dive"`
+}
+
+type StocktakeQuery struct {
+	Status      string `form:"status" binding:"omitempty,oneof=open applied canceled"`
+	WarehouseID *uint  `form:"warehouse_id"`
+}
+
+type StocktakeVarianceQuery struct {
+	// OnlyDiscrepancies оставляет в отчёте только партии с расхождением.
+	OnlyDiscrepancies bool `form:"only_discrepancies"`
+}
+
+type StocktakeLineResponse struct {
+	ID               uint       `json:"id"`
+	BatchID          uint       `json:"batch_id"`
+	VariantID        uint       `json:"variant_id"`
+	MedicineID       uint       `json:"medicine_id"`
+	LotNumber        string     `json:"lot_number"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	ExpectedQuantity int        `json:"expected_quantity"`
+	CountedQuantity  *int       `json:"counted_quantity"`
+	Variance         *int       `json:"variance"`
+	Adjustment       int        `json:"adjustment"`
+	CountedAt        *time.Time `json:"counted_at,omitempty"`
+}
+
+type StocktakeResponse struct {
+	ID          uint                    `json:"id"`
+	WarehouseID uint                    `json:"warehouse_id"`
+	Status      models.StocktakeStatus  `json:"status"`
+	Comment     string                  `json:"comment,omitempty"`
+	CreatedBy   *uint                   `json:"created_by,omitempty"`
+	AppliedBy   *uint                   `json:"applied_by,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	AppliedAt   *time.Time              `json:"applied_at,omitempty"`
+	Lines       []StocktakeLineResponse `json:"lines,omitempty"`
+}
+
+// StocktakeMedicineVariance - расхождения по лекарству. Variance считается
+// только по подсчитанным партиям.
+type StocktakeMedicineVariance struct {
+	MedicineID       uint                    `json:"medicine_id"`
+	Name             string                  `json:"name"`
+	ExpectedQuantity int                     `json:"expected_quantity"`
+	CountedQuantity  int                     `json:"counted_quantity"`
+	Variance         int                     `json:"variance"`
+	UncountedBatches int                     `json:"uncounted_batches"`
+	Batches          []StocktakeLineResponse `json:"batches"`
+}
+
+type StocktakeVarianceResponse struct {
+	StocktakeID    uint                   `json:"stocktake_id"`
+	WarehouseID    uint                   `json:"warehouse_id"`
+	Status         models.StocktakeStatus `json:"status"`
+	TotalBatches   int                    `json:"total_batches"`
+	CountedBatches int                    `json:"counted_batches"`
+	// Shortage и Surplus - суммарная недостача и излишек в штуках.
+	Shortage  int                         `json:"shortage"`
+	Surplus   int                         `json:"surplus"`
+	Medicines []StocktakeMedicineVariance `json:"medicines"`
+}
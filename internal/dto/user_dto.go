@@ -1,5 +1,7 @@
 package dto
 
+import "team-pharmacy/internal/models"
+
 type CreateUserRequest struct {
 	FullName       string `json:"full_name" binding:"required"`
 	Email          string `json:"email" binding:"required,email"`
@@ -14,9 +16,14 @@ type UpdateUserRequest struct {
 	DefaultAddress *string `json:"default_address" binding:"omitempty,max=255"`
 }
 
+type UpdateUserRoleRequest struct {
+	Role models.Role `json:"role" binding:"required,oneof=customer pharmacist admin courier"`
+}
+
 type CreateUserResponse struct {
-	FullName       string `json:"full_name"`
-	Email          string `json:"email"`
-	Phone          string `json:"phone"`
-	DefaultAddress string `json:"default_address"`
+	FullName       string      `json:"full_name"`
+	Email          string      `json:"email"`
+	Phone          string      `json:"phone"`
+	DefaultAddress string      `json:"default_address"`
+	Role           models.Role `json:"role"`
 }
@@ -0,0 +1,64 @@
+package dto
+
+import "time"
+
+type WarehouseCreate struct {
+	Code     string `json:"code" binding:"required,max=32"`
+	Name     string `json:"name" binding:"required,max=255"`
+	City     string `json:"city" binding:"required,max=100"`
+	Address  string `json:"address" binding:"required,max=255"`
+	Priority int    `json:"priority"`
+}
+
+type WarehouseUpdate struct {
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	City     *string `json:"city" binding:"omitempty,max=100"`
+	Address  *string `json:"address" binding:"omitempty,max=255"`
+	Priority *int    `json:"priority"`
+	IsActive *bool   `json:"is_active"`
+}
+
+type WarehouseStockResponse struct {
+	VariantID uint `json:"variant_id"`
+	Quantity  uint `json:"quantity"`
+}
+
+// BranchAvailabilityResponse - наличие фасовок лекарства в одном филиале.
+type BranchAvailabilityResponse struct {
+	WarehouseID uint                     `json:"warehouse_id"`
+	Code        string                   `json:"code"`
+	Name        string                   `json:"name"`
+	City        string                   `json:"city"`
+	Address     string                   `json:"address"`
+	InStock     bool                     `json:"in_stock"`
+	Variants    []WarehouseStockResponse `json:"variants"`
+}
+
+type StockTransferRequest struct {
+	FromWarehouseID uint   `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseID   uint   `json:"to_warehouse_id" binding:"required"`
+	VariantID       uint   `json:"variant_id" binding:"required"`
+	Quantity        uint   `json:"quantity" binding:"required,min=1"`
+	Comment         string `json:"comment" binding:"max=255"`
+}
+
+type StockTransferLineResponse struct {
+	LotNumber   string     `json:"lot_number"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Quantity    uint       `json:"quantity"`
+	FromBatchID uint       `json:"from_batch_id"`
+	ToBatchID   uint       `json:"to_batch_id"`
+}
+
+type StockTransferResponse struct {
+	ID              uint                        `json:"id"`
+	FromWarehouseID uint                        `json:"from_warehouse_id"`
+	ToWarehouseID   uint                        `json:"to_warehouse_id"`
+	VariantID       uint                        `json:"variant_id"`
+	MedicineID      uint                        `json:"medicine_id"`
+	Quantity        uint                        `json:"quantity"`
+	Comment         string                      `json:"comment,omitempty"`
+	CreatedBy       *uint                       `json:"created_by,omitempty"`
+	CreatedAt       time.Time                   `json:"created_at"`
+	Lines           []StockTransferLineResponse `json:"lines"`
+}
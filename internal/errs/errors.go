@@ -12,4 +12,70 @@ var (
 	ErrOrderNotFound           = errors.New("order not found")
 	ErrInvalidStatus           = errors.New("invalid status")
 	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrPaymentAlreadyProcessed = errors.New("payment already processed")
+	ErrOrderNotPayable         = errors.New("order cannot be paid")
+	ErrPromocodeNotFound       = errors.New("promocode not found")
+	ErrPromocodeInactive       = errors.New("promocode is inactive or expired")
+	ErrPromocodeNotApplicable  = errors.New("promocode is not applicable to this cart")
+	ErrPromocodeUsageLimit     = errors.New("promocode usage limit reached")
+	ErrInvalidPromocode        = errors.New("invalid promocode parameters")
+	ErrInsufficientStock       = errors.New("insufficient stock")
+	ErrOrderNotCancelable      = errors.New("order cannot be canceled")
+	ErrPrescriptionRequired    = errors.New("valid prescription required")
+	ErrPrescriptionNotFound    = errors.New("prescription not found")
+	ErrPrescriptionReviewed    = errors.New("prescription already reviewed")
+	ErrInvalidPrescription     = errors.New("prescription must contain a file or an e-prescription code and medicines")
+	ErrUserAlreadyExists       = errors.New("user with this email already exists")
+	ErrInvalidCredentials      = errors.New("invalid email or password")
+	ErrInvalidToken            = errors.New("invalid or expired token")
+	ErrUnauthorized            = errors.New("authorization required")
+	ErrForbidden               = errors.New("access denied")
+	ErrInvalidCatalogQuery     = errors.New("invalid catalog query")
+	ErrNotAnAnalog             = errors.New("medicine is not an analog of the cart item")
+	ErrInvalidInteraction      = errors.New("interaction must reference two different active ingredients")
+	ErrSevereInteraction       = errors.New("cart contains medicines with severe interactions")
+	ErrVariantNotFound         = errors.New("medicine variant not found")
+	ErrVariantRequired         = errors.New("medicine has several variants, variant_id is required")
+	ErrLastVariant             = errors.New("medicine must have at least one variant")
+	ErrVariantHasStock         = errors.New("variant still has stock in batches, write it off before deleting")
+	ErrBatchNotFound           = errors.New("stock batch not found")
+	ErrBatchExpired            = errors.New("stock batch expiry date must be in the future")
+	ErrInvalidStockMovement    = errors.New("write-off quantity must be positive")
+	ErrRecallNotFound          = errors.New("recall not found")
+	ErrRecallLotsNotFound      = errors.New("no active batches of the medicine with these lot numbers")
+	ErrOrderRecalled           = errors.New("order contains items from a recalled lot and can only be canceled")
+	ErrWarehouseNotFound       = errors.New("warehouse not found")
+	ErrInvalidTransfer         = errors.New("transfer requires two different active warehouses")
+	ErrNoFulfilmentWarehouse   = errors.New("no branch has enough stock to fulfil the whole order")
+	ErrSupplierNotFound        = errors.New("supplier not found")
+	ErrPurchaseOrderNotFound   = errors.New("purchase order not found")
+	ErrPurchaseOrderNotDraft   = errors.New("only draft purchase orders can be changed or sent")
+	ErrPurchaseOrderNotSent    = errors.New("goods can be received only for a sent purchase order")
+	ErrInvalidReceipt          = errors.New("received quantity exceeds the ordered quantity of the line")
+	ErrStocktakeNotFound       = errors.New("stocktake not found")
+	ErrStocktakeNotOpen        = errors.New("only an open stocktake can be counted, applied or canceled")
+	ErrStocktakeInProgress     = errors.New("the branch already has an open stocktake")
+	ErrEmptyStocktake          = errors.New("no stock batches to count in the branch")
+	ErrInvalidStocktakeCount   = errors.New("count must reference one batch, variant or medicine of the stocktake; recalled and expired batches are counted by batch")
+	ErrPickupPointNotFound     = errors.New("pickup point not found")
+	ErrInvalidOpeningHours     = errors.New("opening hours must close after they open")
+	ErrPickupPointClosed       = errors.New("pickup point is closed now")
+	ErrDeliveryAddressRequired = errors.New("delivery address is required for delivery orders")
+	ErrOrderNotReadyForPickup  = errors.New("order is not ready for pickup")
+	ErrInvalidPickupCode       = errors.New("invalid pickup code")
+	ErrDeliveryZoneNotFound    = errors.New("delivery zone not found")
+	ErrInvalidDeliveryZone     = errors.New("delivery zone needs postal codes or a polygon of at least three points")
+	ErrOutsideDeliveryZone     = errors.New("address is outside every delivery zone")
+	ErrDeliveryPointMismatch   = errors.New("delivery coordinates do not match the address postal code")
+	ErrBelowMinimumOrder       = errors.New("order total is below the minimum for the delivery zone")
+	ErrDeliverySlotNotFound    = errors.New("delivery slot not found")
+	ErrInvalidDeliverySlot     = errors.New("delivery slot must end after it starts")
+	ErrDeliverySlotUnavailable = errors.New("delivery slot is full, already started or belongs to another zone")
+	ErrShipmentNotFound        = errors.New("shipment not found")
+	ErrShipmentExists          = errors.New("order already has a shipment")
+	ErrShipmentNotAllowed      = errors.New("only paid delivery orders can be shipped")
+	ErrInvalidShipmentState    = errors.New("shipment status does not allow this action")
+	ErrInvalidCourier          = errors.New("courier must be a user with the courier role")
+	ErrCourierRequired         = errors.New("assign a courier or a tracking number before dispatch")
 )
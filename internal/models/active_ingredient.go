@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+type DosageForm string
+
+const (
+	DosageFormTablet      DosageForm = "tablet"
+	DosageFormCapsule     DosageForm = "capsule"
+	DosageFormSyrup       DosageForm = "syrup"
+	DosageFormSuspension  DosageForm = "suspension"
+	DosageFormSolution    DosageForm = "solution"
+	DosageFormInjection   DosageForm = "injection"
+	DosageFormDrops       DosageForm = "drops"
+	DosageFormSpray       DosageForm = "spray"
+	DosageFormOintment    DosageForm = "ointment"
+	DosageFormCream       DosageForm = "cream"
+	DosageFormGel         DosageForm = "gel"
+	DosageFormPowder      DosageForm = "powder"
+	DosageFormSuppository DosageForm = "suppository"
+)
+
+// ActiveIngredient - действующее вещество по международному непатентованному названию (МНН).
+type ActiveIngredient struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	INN       string    `json:"inn" gorm:"type:varchar(150);uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MedicineIngredient - действующее вещество в составе лекарства и его дозировка
+// на единицу формы выпуска (например, 500 mg в таблетке).
+type MedicineIngredient struct {
+	ID                 uint              `json:"-" gorm:"primaryKey"`
+	MedicineID         uint              `json:"-" gorm:"uniqueIndex:idx_medicine_ingredient;not null"`
+	ActiveIngredientID uint              `json:"active_ingredient_id" gorm:"uniqueIndex:idx_medicine_ingredient;not null"`
+	ActiveIngredient   *ActiveIngredient `json:"active_ingredient,omitempty" gorm:"constraint:OnDelete:RESTRICT;"`
+	Strength           float64           `json:"strength" gorm:"type:numeric(12,4);not null"`
+	StrengthUnit       string            `json:"strength_unit" gorm:"type:varchar(16);not null"`
+}
@@ -10,10 +10,14 @@ type Cart struct {
 
 type CartItem struct {
 	gorm.Model
-	CartID       uint      `gorm:"index;not null"`
-	Cart         *Cart     `gorm:"constraint:OnDelete:CASCADE;"`
-	MedicineID   uint      `gorm:"index;not null"`
-	Medicine     *Medicine `gorm:"constraint:OnDelete:CASCADE;"`
-	Quantity     int       `gorm:"not null"`
-	PricePerUnit int64     `gorm:"not null"`
+	CartID       uint             `gorm:"index;not null"`
+	Cart         *Cart            `gorm:"constraint:OnDelete:CASCADE;"`
+	MedicineID   uint             `gorm:"index;not null"`
+	Medicine     *Medicine        `gorm:"constraint:OnDelete:CASCADE;"`
+	VariantID    *uint            `gorm:"index"`
+	Variant      *MedicineVariant `gorm:"constraint:OnDelete:SET NULL;"`
+	Quantity     int              `gorm:"not null"`
+	PricePerUnit int64            `gorm:"not null"`
+	// RecallID - по фасовке позиции объявлен отзыв серии; снимается при замене позиции.
+	RecallID *uint `gorm:"index"`
 }
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GeoPoint - координаты в градусах.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// DeliveryZone - зона доставки. Адрес попадает в зону по почтовому индексу из
+// списка, а без индекса - по координатам внутри многоугольника; зоны проверяются по Priority.
+// Суммы - в тех же единицах, что и цены заказа.
+type DeliveryZone struct {
+	gorm.Model
+	Name          string     `json:"name" gorm:"type:varchar(255);not null"`
+	PostalCodes   []string   `json:"postal_codes" gorm:"type:jsonb;serializer:json"`
+	Polygon       []GeoPoint `json:"polygon" gorm:"type:jsonb;serializer:json"`
+	Fee           int64      `json:"fee" gorm:"not null;default:0"`
+	MinOrderValue int64      `json:"min_order_value" gorm:"not null;default:0"`
+	// FreeDeliveryFrom - сумма товаров, начиная с которой доставка бесплатна; nil - всегда платная.
+	FreeDeliveryFrom *int64 `json:"free_delivery_from"`
+	// Priority - при пересечении зон выбирается зона с меньшим значением.
+	Priority int  `json:"priority" gorm:"not null;default:0"`
+	IsActive bool `json:"is_active" gorm:"not null;default:true"`
+}
+
+// HasPostalCode - индекс входит в список индексов зоны.
+func (z *DeliveryZone) HasPostalCode(postalCode string) bool {
+	return postalCode != "" && slices.Contains(z.PostalCodes, postalCode)
+}
+
+// Contains - точка внутри многоугольника зоны (метод трассировки луча).
+func (z *DeliveryZone) Contains(p GeoPoint) bool {
+	if len(z.Polygon) < 3 {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(z.Polygon)-1; i < len(z.Polygon); j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// FeeFor - стоимость доставки заказа с суммой товаров amount.
+func (z *DeliveryZone) FeeFor(amount int64) int64 {
+	if z.FreeDeliveryFrom != nil && amount >= *z.FreeDeliveryFrom {
+		return 0
+	}
+	return z.Fee
+}
+
+// DeliverySlot - интервал доставки в зоне. Reserved - число заказов, занявших
+// слот; отмена заказа освобождает место.
+type DeliverySlot struct {
+	gorm.Model
+	ZoneID   uint          `json:"zone_id" gorm:"index:idx_delivery_slot_zone_start;not null"`
+	Zone     *DeliveryZone `json:"-" gorm:"constraint:OnDelete:RESTRICT;"`
+	StartsAt time.Time     `json:"starts_at" gorm:"index:idx_delivery_slot_zone_start;not null"`
+	EndsAt   time.Time     `json:"ends_at" gorm:"not null"`
+	Capacity uint          `json:"capacity" gorm:"not null"`
+	Reserved uint          `json:"reserved" gorm:"not null;default:0"`
+	IsActive bool          `json:"is_active" gorm:"not null;default:true"`
+}
+
+// IsAvailableAt - на слот ещё можно записаться в момент t.
+func (s *DeliverySlot) IsAvailableAt(t time.Time) bool {
+	return s.IsActive && s.StartsAt.After(t) && s.Reserved < s.Capacity
+}
@@ -0,0 +1,82 @@
+package models
+
+import "testing"
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestDeliveryZoneContains(t *testing.T) {
+	// квадрат 55..56 с.ш., 37..38 в.д. с вырезом: точка (55.9, 37.9) снаружи
+	zone := &DeliveryZone{Polygon: []GeoPoint{
+		{Lat: 55, Lng: 37},
+		{Lat: 55, Lng: 38},
+		{Lat: 55.8, Lng: 38},
+		{Lat: 55.8, Lng: 37.8},
+		{Lat: 56, Lng: 37.8},
+		{Lat: 56, Lng: 37},
+	}}
+
+	tests := []struct {
+		name  string
+		point GeoPoint
+		want  bool
+	}{
+		{"center", GeoPoint{Lat: 55.5, Lng: 37.5}, true},
+		{"inside the narrow part", GeoPoint{Lat: 55.9, Lng: 37.4}, true},
+		{"in the cut-out", GeoPoint{Lat: 55.9, Lng: 37.9}, false},
+		{"north", GeoPoint{Lat: 56.5, Lng: 37.5}, false},
+		{"west", GeoPoint{Lat: 55.5, Lng: 36.5}, false},
+		{"east", GeoPoint{Lat: 55.5, Lng: 38.5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zone.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%+v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryZoneContainsWithoutPolygon(t *testing.T) {
+	zone := &DeliveryZone{Polygon: []GeoPoint{{Lat: 55, Lng: 37}, {Lat: 56, Lng: 38}}}
+	if zone.Contains(GeoPoint{Lat: 55.5, Lng: 37.5}) {
+		t.Error("zone with less than three vertices must not contain any point")
+	}
+}
+
+func TestDeliveryZoneFeeFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		zone   DeliveryZone
+		amount int64
+		want   int64
+	}{
+		{"always paid", DeliveryZone{Fee: 300}, 100000, 300},
+		{"below free threshold", DeliveryZone{Fee: 300, FreeDeliveryFrom: int64Ptr(5000)}, 4999, 300},
+		{"at free threshold", DeliveryZone{Fee: 300, FreeDeliveryFrom: int64Ptr(5000)}, 5000, 0},
+		{"above free threshold", DeliveryZone{Fee: 300, FreeDeliveryFrom: int64Ptr(5000)}, 7000, 0},
+		{"free zone", DeliveryZone{}, 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.zone.FeeFor(tt.amount); got != tt.want {
+				t.Errorf("FeeFor(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryZoneHasPostalCode(t *testing.T) {
+	zone := &DeliveryZone{PostalCodes: []string{"101000", "101001"}}
+
+	if !zone.HasPostalCode("101001") {
+		t.Error("listed postal code must match")
+	}
+	if zone.HasPostalCode("102000") {
+		t.Error("unlisted postal code must not match")
+	}
+	if zone.HasPostalCode("") {
+		t.Error("empty postal code must not match")
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type InteractionSeverity string
+
+const (
+	InteractionSeverityMinor    InteractionSeverity = "minor"
+	InteractionSeverityModerate InteractionSeverity = "moderate"
+	InteractionSeveritySevere   InteractionSeverity = "severe"
+)
+
+func (s InteractionSeverity) IsValid() bool {
+	switch s {
+	case InteractionSeverityMinor, InteractionSeverityModerate, InteractionSeveritySevere:
+		return true
+	default:
+		return false
+	}
+}
+
+// DrugInteraction - взаимодействие двух действующих веществ. Пара хранится
+// упорядоченной (IngredientAID < IngredientBID), чтобы не было дублей.
+type DrugInteraction struct {
+	ID            uint                `json:"id" gorm:"primaryKey"`
+	IngredientAID uint                `json:"ingredient_a_id" gorm:"uniqueIndex:idx_drug_interaction_pair;not null"`
+	IngredientA   *ActiveIngredient   `json:"ingredient_a,omitempty" gorm:"foreignKey:IngredientAID;constraint:OnDelete:CASCADE;"`
+	IngredientBID uint                `json:"ingredient_b_id" gorm:"uniqueIndex:idx_drug_interaction_pair;not null"`
+	IngredientB   *ActiveIngredient   `json:"ingredient_b,omitempty" gorm:"foreignKey:IngredientBID;constraint:OnDelete:CASCADE;"`
+	Severity      InteractionSeverity `json:"severity" gorm:"type:varchar(16);not null"`
+	Description   string              `json:"description" gorm:"type:text;not null"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
@@ -1,22 +1,39 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// Medicine - товар каталога. Price и StockQuantity - минимальная цена и суммарный
+// остаток по вариантам (Variants), их пересчитывает репозиторий.
 type Medicine struct {
 	gorm.Model
-	Name                 string  `json:"name" gorm:"not null,size:100"`
-	Description          string  `json:"description" gorm:"size:500"`
-	Price                uint64 `json:"price" gorm:"not null"`
-	InStock              bool    `json:"in_stock" gorm:"not null"`
-	StockQuantity        uint    `json:"stock_quantity" gorm:"not null"`
-	CategoryID           *uint   `json:"category_id"`
-	SubcategoryID        *uint   `json:"subcategory_id"`
-	Manufacturer         string  `json:"manufacturer" gorm:"size:150,not null"`
-	PrescriptionRequired bool    `json:"prescription_required"`
-	AvgRating            float64 `json:"avg_rating" gorm:"index,not null check:rating>=1 AND rating<=10"`
+	Name                 string     `json:"name" gorm:"not null,size:100"`
+	Description          string     `json:"description" gorm:"size:500"`
+	Price                uint64     `json:"price" gorm:"not null"`
+	InStock              bool       `json:"in_stock" gorm:"not null"`
+	StockQuantity        uint       `json:"stock_quantity" gorm:"not null"`
+	CategoryID           *uint      `json:"category_id"`
+	SubcategoryID        *uint      `json:"subcategory_id"`
+	Manufacturer         string     `json:"manufacturer" gorm:"size:150,not null"`
+	PrescriptionRequired bool       `json:"prescription_required"`
+	DosageForm           DosageForm `json:"dosage_form" gorm:"type:varchar(32)"`
+	AvgRating            float64    `json:"avg_rating" gorm:"index,not null check:rating>=1 AND rating<=10"`
+	// ReorderPoint - при остатке не выше этого значения лекарство считается
+	// заканчивающимся; 0 отключает контроль.
+	ReorderPoint uint `json:"reorder_point" gorm:"not null;default:0"`
+	// LowStockAlertedAt - когда отправлено оповещение о низком остатке; сбрасывается,
+	// когда остаток снова выше ReorderPoint.
+	LowStockAlertedAt *time.Time `json:"-"`
+	// SearchVector заполняется репозиторием через to_tsvector, gorm его не читает и не пишет.
+	SearchVector string `json:"-" gorm:"type:tsvector;->:false;<-:false"`
 
-	Category    Category    `json:"category" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Subcategory Subcategory `json:"subcategory" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Category    Category             `json:"category" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Subcategory Subcategory          `json:"subcategory" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Ingredients []MedicineIngredient `json:"ingredients,omitempty" gorm:"foreignKey:MedicineID;constraint:OnDelete:CASCADE;"`
+	Variants    []MedicineVariant    `json:"variants,omitempty" gorm:"foreignKey:MedicineID"`
 }
 
 func (m *Medicine) BeforeSave(tx *gorm.DB) error {
@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+// DefaultVariantName - название фасовки, которая создаётся, если у лекарства
+// при добавлении не указаны варианты.
+const DefaultVariantName = "Основная фасовка"
+
+// MedicineVariant - фасовка лекарства (SKU) со своей ценой, остатком и штрихкодом.
+// Отзывы и рейтинг остаются на уровне Medicine. StockQuantity - сумма остатков
+// непросроченных партий (StockBatch), её пересчитывает репозиторий.
+type MedicineVariant struct {
+	gorm.Model
+	MedicineID    uint      `json:"medicine_id" gorm:"index;not null"`
+	Medicine      *Medicine `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	SKU           string    `json:"sku" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Name          string    `json:"name" gorm:"type:varchar(100);not null"`
+	Barcode       *string   `json:"barcode,omitempty" gorm:"type:varchar(32);uniqueIndex"`
+	Price         uint64    `json:"price" gorm:"not null"`
+	StockQuantity uint      `json:"stock_quantity" gorm:"not null"`
+	InStock       bool      `json:"in_stock" gorm:"not null"`
+}
+
+func (v *MedicineVariant) BeforeSave(tx *gorm.DB) error {
+	v.InStock = v.StockQuantity > 0
+	return nil
+}
@@ -1,9 +1,19 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// FulfilmentMode - способ получения заказа.
+type FulfilmentMode string
+
+const (
+	FulfilmentDelivery FulfilmentMode = "delivery"
+	FulfilmentPickup   FulfilmentMode = "pickup"
+)
+
 type Order struct {
 	gorm.Model
 	UserID uint `gorm:"index;not null"`
@@ -14,12 +24,39 @@ type Order struct {
 	TotalPrice    int64 `gorm:"not null"`
 	DiscountTotal int64 `gorm:"not null"`
 	FinalPrice    int64 `gorm:"not null"`
+	PromocodeID   *uint `gorm:"index"`
+	// DeliveryFee - стоимость доставки, входит в FinalPrice.
+	DeliveryFee    int64 `gorm:"not null;default:0"`
+	DeliveryZoneID *uint `gorm:"index"`
+	// DeliverySlotID - выбранный покупателем интервал доставки.
+	DeliverySlotID *uint         `gorm:"index"`
+	DeliverySlot   *DeliverySlot `gorm:"constraint:OnDelete:RESTRICT;"`
+
+	// FulfilmentMode - доставка или самовывоз; у заказа на самовывоз адрес доставки пуст.
+	FulfilmentMode  FulfilmentMode `gorm:"type:varchar(16);not null;default:'delivery'"`
+	DeliveryAddress string         `gorm:"not null"`
+	Comment         string         `gorm:"type:varchar(255)"`
 
-	DeliveryAddress string `gorm:"not null"`
-	Comment         string `gorm:"type:varchar(255)"`
+	PickupPointID *uint        `gorm:"index"`
+	PickupPoint   *PickupPoint `gorm:"constraint:OnDelete:RESTRICT;"`
+	// PickupCode - код, который покупатель называет при получении заказа в пункте.
+	PickupCode string `gorm:"type:varchar(8)"`
 
-	Items []OrderItem `gorm:"constraint:OnDelete:CASCADE;"`
-	//Payments []Payment   `gorm:"constraint:OnDelete:CASCADE;"`
+	// WarehouseID - филиал, из остатков которого собран заказ.
+	WarehouseID *uint `gorm:"index"`
+
+	CanceledAt   *time.Time
+	CanceledBy   *uint
+	CancelReason string `gorm:"type:varchar(255)"`
+
+	// RecallID - в заказ попал товар из отозванной серии, заказ ещё не отгружен.
+	RecallID *uint `gorm:"index"`
+
+	Items    []OrderItem `gorm:"constraint:OnDelete:CASCADE;"`
+	Payments []Payment   `gorm:"constraint:OnDelete:CASCADE;"`
+	Refunds  []Refund    `gorm:"constraint:OnDelete:CASCADE;"`
+	Events   []OrderEvent
+	Shipment *Shipment
 }
 
 type OrderItem struct {
@@ -27,9 +64,14 @@ type OrderItem struct {
 	OrderID    uint   `gorm:"index;not null"`
 	Order      *Order `gorm:"constraint:OnDelete:CASCADE;"`
 	MedicineID uint   `gorm:"index;not null"`
+	VariantID  *uint  `gorm:"index"`
 
 	MedicineName string `gorm:"type:varchar(255);not null"`
+	VariantName  string `gorm:"type:varchar(100)"`
+	SKU          string `gorm:"type:varchar(64)"`
 	Quantity     int    `gorm:"not null"`
 	PricePerUnit int64  `gorm:"not null"`
 	LineTotal    int64  `gorm:"not null"`
+
+	Batches []OrderItemBatch `gorm:"constraint:OnDelete:CASCADE;"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OrderEvent - запись журнала переходов заказа между статусами. Записи только добавляются.
+type OrderEvent struct {
+	ID         uint        `json:"id" gorm:"primaryKey"`
+	OrderID    uint        `json:"order_id" gorm:"not null;index"`
+	FromStatus OrderStatus `json:"from_status" gorm:"type:varchar(32)"`
+	ToStatus   OrderStatus `json:"to_status" gorm:"type:varchar(32);not null"`
+	ActorID    *uint       `json:"actor_id" gorm:"index"`
+	Reason     string      `json:"reason" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"index"`
+
+	Order *Order `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
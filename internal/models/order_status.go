@@ -8,13 +8,44 @@ const (
 	OrderStatusPaid           OrderStatus = "paid"
 	OrderStatusCanceled       OrderStatus = "canceled"
 	OrderStatusShipped        OrderStatus = "shipped"
+	OrderStatusReadyForPickup OrderStatus = "ready_for_pickup"
 	OrderStatusCompleted      OrderStatus = "completed"
 )
 
 var allowedOrderStatusTransitions = map[OrderStatus][]OrderStatus{
 	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCanceled},
-	OrderStatusPaid:           {OrderStatusShipped},
+	OrderStatusPaid:           {OrderStatusShipped, OrderStatusReadyForPickup, OrderStatusCanceled},
 	OrderStatusShipped:        {OrderStatusCompleted},
+	// невостребованный заказ отменяется, товар возвращается в остатки филиала
+	OrderStatusReadyForPickup: {OrderStatusCompleted, OrderStatusCanceled},
+}
+
+// fulfilmentOnlyStatuses - статусы, доступные только заказам с этим способом получения.
+var fulfilmentOnlyStatuses = map[OrderStatus]FulfilmentMode{
+	OrderStatusShipped:        FulfilmentDelivery,
+	OrderStatusReadyForPickup: FulfilmentPickup,
+}
+
+type orderStatusTransition struct {
+	from, to OrderStatus
+}
+
+// orderStatusTransitionRoles - какие роли могут вручную переводить заказ между статусами.
+// Оплата и отмена идут через платежи и CancelOrder, отгрузка и вручение - через
+// отправление (Shipment), выдача из пункта самовывоза - по коду получения,
+// и здесь не описаны.
+var orderStatusTransitionRoles = map[orderStatusTransition][]Role{
+	{OrderStatusPendingPayment, OrderStatusPaid}: {RoleAdmin},
+	{OrderStatusPaid, OrderStatusReadyForPickup}: {RoleAdmin, RolePharmacist},
+}
+
+func CanRoleChangeOrderStatus(role Role, from, to OrderStatus) bool {
+	for _, r := range orderStatusTransitionRoles[orderStatusTransition{from, to}] {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 func CanChangeOrderStatus(from, to OrderStatus) bool {
@@ -29,3 +60,19 @@ func CanChangeOrderStatus(from, to OrderStatus) bool {
 	}
 	return false
 }
+
+// CanChangeStatus - переход допустим и подходит способу получения заказа.
+func (o *Order) CanChangeStatus(to OrderStatus) bool {
+	if mode, ok := fulfilmentOnlyStatuses[to]; ok && o.Fulfilment() != mode {
+		return false
+	}
+	return CanChangeOrderStatus(o.Status, to)
+}
+
+// Fulfilment - способ получения; заказы, созданные до самовывоза, считаются доставкой.
+func (o *Order) Fulfilment() FulfilmentMode {
+	if o.FulfilmentMode == "" {
+		return FulfilmentDelivery
+	}
+	return o.FulfilmentMode
+}
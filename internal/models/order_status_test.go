@@ -0,0 +1,83 @@
+package models
+
+import "testing"
+
+func TestCanChangeOrderStatus(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderStatusPendingPayment, OrderStatusPaid, true},
+		{OrderStatusPendingPayment, OrderStatusCanceled, true},
+		{OrderStatusPendingPayment, OrderStatusShipped, false},
+		{OrderStatusPaid, OrderStatusShipped, true},
+		{OrderStatusPaid, OrderStatusReadyForPickup, true},
+		{OrderStatusPaid, OrderStatusCanceled, true},
+		{OrderStatusPaid, OrderStatusCompleted, false},
+		{OrderStatusShipped, OrderStatusCompleted, true},
+		{OrderStatusShipped, OrderStatusCanceled, false},
+		{OrderStatusReadyForPickup, OrderStatusCompleted, true},
+		{OrderStatusReadyForPickup, OrderStatusCanceled, true},
+		{OrderStatusCompleted, OrderStatusCanceled, false},
+		{OrderStatusCanceled, OrderStatusPaid, false},
+		{OrderStatusDraft, OrderStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanChangeOrderStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanChangeOrderStatus(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestOrderCanChangeStatusByFulfilment(t *testing.T) {
+	tests := []struct {
+		name string
+		mode FulfilmentMode
+		to   OrderStatus
+		want bool
+	}{
+		{"delivery ships", FulfilmentDelivery, OrderStatusShipped, true},
+		{"delivery is not ready for pickup", FulfilmentDelivery, OrderStatusReadyForPickup, false},
+		{"pickup is ready for pickup", FulfilmentPickup, OrderStatusReadyForPickup, true},
+		{"pickup does not ship", FulfilmentPickup, OrderStatusShipped, false},
+		{"old order ships as delivery", "", OrderStatusShipped, true},
+		{"old order is not ready for pickup", "", OrderStatusReadyForPickup, false},
+		{"any mode cancels", FulfilmentPickup, OrderStatusCanceled, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &Order{Status: OrderStatusPaid, FulfilmentMode: tt.mode}
+			if got := order.CanChangeStatus(tt.to); got != tt.want {
+				t.Errorf("CanChangeStatus(%s) = %v, want %v", tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanRoleChangeOrderStatus(t *testing.T) {
+	tests := []struct {
+		role     Role
+		from, to OrderStatus
+		want     bool
+	}{
+		{RoleAdmin, OrderStatusPendingPayment, OrderStatusPaid, true},
+		{RolePharmacist, OrderStatusPendingPayment, OrderStatusPaid, false},
+		{RoleCustomer, OrderStatusPendingPayment, OrderStatusPaid, false},
+		{RoleAdmin, OrderStatusPaid, OrderStatusReadyForPickup, true},
+		{RolePharmacist, OrderStatusPaid, OrderStatusReadyForPickup, true},
+		{RoleCourier, OrderStatusPaid, OrderStatusReadyForPickup, false},
+		{RoleCustomer, OrderStatusPaid, OrderStatusReadyForPickup, false},
+		// отгрузка и вручение идут через отправление, а не вручную
+		{RoleAdmin, OrderStatusPaid, OrderStatusShipped, false},
+		{RoleCourier, OrderStatusShipped, OrderStatusCompleted, false},
+		{RoleAdmin, OrderStatusPaid, OrderStatusCanceled, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanRoleChangeOrderStatus(tt.role, tt.from, tt.to); got != tt.want {
+			t.Errorf("CanRoleChangeOrderStatus(%s, %s, %s) = %v, want %v", tt.role, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -8,12 +8,14 @@ import (
 
 type Payment struct {
 	gorm.Model
-	OrderID uint `json:"order_id" gorm:"not null,index"`
-	Amount uint `json:"amount" gorm:"not null"`
-	Status string `json:"status" gorm:"not null,size:31,index"`
-	Method string `json:"method" gorm:"not null,size:31,index"`
-	PaidAT time.Time `json:"paid_at"`
+	OrderID       uint       `json:"order_id" gorm:"not null;index"`
+	Amount        int64      `json:"amount" gorm:"not null"`
+	Status        Status     `json:"status" gorm:"type:varchar(31);not null;index"`
+	Method        string     `json:"method" gorm:"type:varchar(31);not null;index"`
+	Provider      string     `json:"provider" gorm:"type:varchar(31);not null"`
+	ExternalID    string     `json:"external_id" gorm:"type:varchar(64);index"`
+	FailureReason string     `json:"failure_reason" gorm:"type:varchar(255)"`
+	PaidAT        *time.Time `json:"paid_at"`
 
-	Order Order `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-
-}
\ No newline at end of file
+	Order *Order `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
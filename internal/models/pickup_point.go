@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OpeningHours - часы работы в один день недели, время в формате HH:MM.
+type OpeningHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+// PickupPoint - пункт самовывоза. Заказ на самовывоз собирается из остатков
+// филиала, к которому привязан пункт.
+type PickupPoint struct {
+	gorm.Model
+	WarehouseID  uint           `json:"warehouse_id" gorm:"index;not null"`
+	Warehouse    *Warehouse     `json:"-" gorm:"constraint:OnDelete:RESTRICT;"`
+	Name         string         `json:"name" gorm:"type:varchar(255);not null"`
+	City         string         `json:"city" gorm:"type:varchar(100);not null;index"`
+	Address      string         `json:"address" gorm:"type:varchar(255);not null"`
+	Phone        string         `json:"phone" gorm:"type:varchar(20)"`
+	OpeningHours []OpeningHours `json:"opening_hours" gorm:"type:jsonb;serializer:json"`
+	IsActive     bool           `json:"is_active" gorm:"not null;default:true"`
+}
+
+// IsOpenAt - пункт работает в момент t (по местному времени t).
+func (p *PickupPoint) IsOpenAt(t time.Time) bool {
+	clock := t.Format("15:04")
+	for _, hours := range p.OpeningHours {
+		if hours.Weekday == t.Weekday() && hours.Opens <= clock && clock < hours.Closes {
+			return true
+		}
+	}
+	return false
+}
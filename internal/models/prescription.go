@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PrescriptionStatus string
+
+const (
+	PrescriptionStatusPending  PrescriptionStatus = "pending"
+	PrescriptionStatusApproved PrescriptionStatus = "approved"
+	PrescriptionStatusRejected PrescriptionStatus = "rejected"
+)
+
+func (s PrescriptionStatus) IsValid() bool {
+	switch s {
+	case PrescriptionStatusPending, PrescriptionStatusApproved, PrescriptionStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// Prescription - рецепт пользователя: загруженный скан или код электронного рецепта.
+// Путь к скану на сервере наружу не отдаётся, файл скачивается сотрудником
+// через GET /prescriptions/:id/file.
+type Prescription struct {
+	gorm.Model
+	UserID        uint               `json:"user_id" gorm:"not null;index"`
+	FilePath      string             `json:"-" gorm:"type:varchar(255)"`
+	Code          string             `json:"code" gorm:"type:varchar(64);index"`
+	Status        PrescriptionStatus `json:"status" gorm:"type:varchar(16);not null;index"`
+	ValidUntil    *time.Time         `json:"valid_until"`
+	ReviewedBy    *uint              `json:"reviewed_by"`
+	ReviewedAt    *time.Time         `json:"reviewed_at"`
+	ReviewComment string             `json:"review_comment" gorm:"type:varchar(255)"`
+
+	User      *User      `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	Medicines []Medicine `json:"medicines" gorm:"many2many:prescription_medicines;"`
+}
+
+// IsValidAt - рецепт одобрен фармацевтом и ещё не истёк.
+func (p *Prescription) IsValidAt(t time.Time) bool {
+	if p.Status != PrescriptionStatusApproved {
+		return false
+	}
+	return p.ValidUntil == nil || t.Before(*p.ValidUntil)
+}
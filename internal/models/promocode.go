@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercent DiscountType = "percent"
+	DiscountTypeFixed   DiscountType = "fixed"
+)
+
+type Promocode struct {
+	gorm.Model
+	Code          string       `json:"code" gorm:"type:varchar(64);uniqueIndex;not null"`
+	DiscountType  DiscountType `json:"discount_type" gorm:"type:varchar(16);not null"`
+	Value         int64        `json:"value" gorm:"not null"`
+	MinOrderTotal int64        `json:"min_order_total" gorm:"not null;default:0"`
+	ValidFrom     *time.Time   `json:"valid_from"`
+	ValidUntil    *time.Time   `json:"valid_until"`
+	UsageLimit    int          `json:"usage_limit" gorm:"not null;default:0"`
+	PerUserLimit  int          `json:"per_user_limit" gorm:"not null;default:0"`
+	UsedCount     int          `json:"used_count" gorm:"not null;default:0"`
+	IsActive      bool         `json:"is_active" gorm:"not null;default:true"`
+
+	CategoryID *uint `json:"category_id" gorm:"index"`
+	MedicineID *uint `json:"medicine_id" gorm:"index"`
+}
+
+// IsValidAt проверяет, что промокод включён и попадает в окно действия.
+func (p *Promocode) IsValidAt(t time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.ValidFrom != nil && t.Before(*p.ValidFrom) {
+		return false
+	}
+	if p.ValidUntil != nil && t.After(*p.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// PromocodeUsage - применение промокода к заказу. При отмене заказа запись
+// не удаляется, а помечается ReleasedAt и больше не учитывается в лимитах.
+type PromocodeUsage struct {
+	gorm.Model
+	PromocodeID uint       `json:"promocode_id" gorm:"index;not null"`
+	UserID      uint       `json:"user_id" gorm:"index;not null"`
+	OrderID     uint       `json:"order_id" gorm:"index;not null"`
+	Discount    int64      `json:"discount" gorm:"not null"`
+	ReleasedAt  *time.Time `json:"released_at"`
+
+	Promocode *Promocode `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	Order     *Order     `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Supplier struct {
+	gorm.Model
+	Name     string  `json:"name" gorm:"type:varchar(255);not null"`
+	TaxID    *string `json:"tax_id" gorm:"type:varchar(20);uniqueIndex"`
+	Email    string  `json:"email" gorm:"type:varchar(255)"`
+	Phone    string  `json:"phone" gorm:"type:varchar(20)"`
+	Address  string  `json:"address" gorm:"type:varchar(255)"`
+	IsActive bool    `json:"is_active" gorm:"not null;default:true"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSent              PurchaseOrderStatus = "sent"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+)
+
+// PurchaseOrder - заказ поставщику на пополнение филиала. Изменять можно только
+// черновик; после отправки товар принимается по строкам, возможно частями.
+type PurchaseOrder struct {
+	gorm.Model
+	SupplierID  uint                `gorm:"index;not null"`
+	Supplier    *Supplier           `gorm:"constraint:OnDelete:RESTRICT;"`
+	WarehouseID uint                `gorm:"index;not null"`
+	Warehouse   *Warehouse          `gorm:"constraint:OnDelete:RESTRICT;"`
+	Status      PurchaseOrderStatus `gorm:"type:varchar(32);not null;index"`
+	ExpectedAt  *time.Time          `gorm:"type:date"`
+	Comment     string              `gorm:"type:varchar(255)"`
+	CreatedBy   *uint
+	SentAt      *time.Time
+	ReceivedAt  *time.Time
+
+	Lines []PurchaseOrderLine `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+type PurchaseOrderLine struct {
+	ID               uint             `gorm:"primaryKey"`
+	PurchaseOrderID  uint             `gorm:"index;not null"`
+	VariantID        uint             `gorm:"index;not null"`
+	Variant          *MedicineVariant `gorm:"constraint:OnDelete:RESTRICT;"`
+	MedicineID       uint             `gorm:"index;not null"`
+	Quantity         uint             `gorm:"not null"`
+	ReceivedQuantity uint             `gorm:"not null;default:0"`
+	UnitCost         uint64           `gorm:"not null"`
+}
+
+// CanReceive - товар принимается только по отправленному поставщику заказу.
+func (s PurchaseOrderStatus) CanReceive() bool {
+	return s == PurchaseOrderSent || s == PurchaseOrderPartiallyReceived
+}
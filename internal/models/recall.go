@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recall - отзыв серий лекарства производителем. Партии отозванных серий
+// снимаются с продажи, а покупатели, к которым они могли попасть, попадают
+// в список оповещения.
+type Recall struct {
+	gorm.Model
+	MedicineID uint      `gorm:"index;not null"`
+	Medicine   *Medicine `gorm:"constraint:OnDelete:RESTRICT;"`
+	Reason     string    `gorm:"type:text;not null"`
+	CreatedBy  *uint
+
+	Lots          []RecallLot          `gorm:"constraint:OnDelete:CASCADE;"`
+	Notifications []RecallNotification `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// RecallFlaggedOrderStatuses - заказы в этих статусах ещё не отгружены и не выданы,
+// поэтому при отзыве они помечаются и могут быть остановлены.
+var RecallFlaggedOrderStatuses = []OrderStatus{OrderStatusPendingPayment, OrderStatusPaid, OrderStatusReadyForPickup}
+
+type RecallLot struct {
+	ID        uint   `gorm:"primaryKey"`
+	RecallID  uint   `gorm:"uniqueIndex:idx_recall_lot;not null"`
+	LotNumber string `gorm:"type:varchar(64);uniqueIndex:idx_recall_lot;not null"`
+}
+
+// RecallNoticeSource - откуда покупатель попал в список оповещения.
+type RecallNoticeSource string
+
+const (
+	// RecallNoticeOrder - товар из отозванной серии есть в заказе покупателя.
+	RecallNoticeOrder RecallNoticeSource = "order"
+	// RecallNoticeCart - фасовка с отозванной серией лежит в корзине.
+	RecallNoticeCart RecallNoticeSource = "cart"
+)
+
+type RecallNotification struct {
+	ID       uint               `gorm:"primaryKey"`
+	RecallID uint               `gorm:"index;not null"`
+	UserID   uint               `gorm:"index;not null"`
+	User     *User              `gorm:"constraint:OnDelete:CASCADE;"`
+	Source   RecallNoticeSource `gorm:"type:varchar(16);not null"`
+	// OrderID заполнен для Source = order, CartID - для Source = cart.
+	OrderID     *uint
+	OrderStatus OrderStatus `gorm:"type:varchar(32)"`
+	CartID      *uint
+	CreatedAt   time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken хранит отпечаток выданного refresh-токена. При обновлении токен
+// отзывается и заменяется новым; повторное предъявление отозванного токена
+// считается утечкой и отзывает все токены пользователя.
+type RefreshToken struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;index"`
+	TokenHash    string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt    time.Time  `gorm:"not null"`
+	RevokedAt    *time.Time `gorm:"index"`
+	ReplacedByID *uint
+
+	User *User `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (t *RefreshToken) IsActiveAt(at time.Time) bool {
+	return t.RevokedAt == nil && at.Before(t.ExpiresAt)
+}
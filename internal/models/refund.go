@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+type Refund struct {
+	gorm.Model
+	PaymentID  uint   `json:"payment_id" gorm:"not null;index"`
+	OrderID    uint   `json:"order_id" gorm:"not null;index"`
+	Amount     int64  `json:"amount" gorm:"not null"`
+	Status     Status `json:"status" gorm:"type:varchar(31);not null;index"`
+	ExternalID string `json:"external_id" gorm:"type:varchar(64)"`
+	Reason     string `json:"reason" gorm:"type:varchar(255)"`
+
+	Payment *Payment `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
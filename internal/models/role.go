@@ -0,0 +1,24 @@
+package models
+
+type Role string
+
+const (
+	RoleCustomer   Role = "customer"
+	RolePharmacist Role = "pharmacist"
+	RoleAdmin      Role = "admin"
+	RoleCourier    Role = "courier"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleCustomer, RolePharmacist, RoleAdmin, RoleCourier:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStaff - сотрудники аптеки видят и обрабатывают заказы всех пользователей.
+func (r Role) IsStaff() bool {
+	return r == RolePharmacist || r == RoleAdmin
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ShipmentStatus string
+
+const (
+	ShipmentCreated   ShipmentStatus = "created"
+	ShipmentInTransit ShipmentStatus = "in_transit"
+	ShipmentDelivered ShipmentStatus = "delivered"
+)
+
+// Shipment - отправление заказа с доставкой. Передача курьеру переводит заказ
+// в shipped, успешная попытка вручения - в completed.
+type Shipment struct {
+	gorm.Model
+	OrderID   uint   `gorm:"uniqueIndex;not null"`
+	Order     *Order `gorm:"constraint:OnDelete:CASCADE;"`
+	CourierID *uint  `gorm:"index"`
+	Courier   *User  `gorm:"constraint:OnDelete:SET NULL;"`
+	// Carrier - служба доставки; для своих курьеров пусто.
+	Carrier        string         `gorm:"type:varchar(64)"`
+	TrackingNumber *string        `gorm:"type:varchar(64);uniqueIndex"`
+	Status         ShipmentStatus `gorm:"type:varchar(16);not null;index"`
+	CreatedBy      *uint
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+
+	Attempts []DeliveryAttempt `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+type DeliveryAttemptOutcome string
+
+const (
+	DeliveryAttemptDelivered DeliveryAttemptOutcome = "delivered"
+	DeliveryAttemptFailed    DeliveryAttemptOutcome = "failed"
+)
+
+// DeliveryAttempt - попытка вручения заказа курьером.
+type DeliveryAttempt struct {
+	ID         uint                   `gorm:"primaryKey"`
+	ShipmentID uint                   `gorm:"index;not null"`
+	CourierID  *uint                  `gorm:"index"`
+	Outcome    DeliveryAttemptOutcome `gorm:"type:varchar(16);not null"`
+	Comment    string                 `gorm:"type:varchar(255)"`
+	CreatedAt  time.Time
+}
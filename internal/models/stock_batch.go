@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LegacyLotNumber - серия партии, в которую перенесён остаток, заведённый
+// до учёта партий.
+const LegacyLotNumber = "LEGACY"
+
+// StockBatch - партия фасовки: серия, срок годности и текущий остаток.
+// Остаток варианта (MedicineVariant.StockQuantity) - сумма непросроченных партий.
+type StockBatch struct {
+	gorm.Model
+	VariantID  uint             `json:"variant_id" gorm:"index;not null"`
+	Variant    *MedicineVariant `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	MedicineID uint             `json:"medicine_id" gorm:"index;not null"`
+	// WarehouseID - филиал, где лежит партия. Колонка допускает NULL только
+	// для строк до MigrateWarehouses.
+	WarehouseID uint   `json:"warehouse_id" gorm:"index"`
+	LotNumber   string `json:"lot_number" gorm:"type:varchar(64);not null;index"`
+	// ExpiresAt пуст только у партий LEGACY, для которых срок неизвестен.
+	ExpiresAt        *time.Time `json:"expires_at" gorm:"type:date;index"`
+	Quantity         uint       `json:"quantity" gorm:"not null"`
+	ReceivedQuantity uint       `json:"received_quantity" gorm:"not null"`
+	// RecallID заполняется при отзыве серии; такая партия больше не продаётся.
+	RecallID *uint `json:"recall_id" gorm:"index"`
+}
+
+// IsSellableAt - партию можно продавать, пока она не отозвана и не наступил срок годности.
+func (b *StockBatch) IsSellableAt(t time.Time) bool {
+	return b.RecallID == nil && (b.ExpiresAt == nil || b.ExpiresAt.After(t))
+}
+
+// OrderItemBatch - сколько единиц позиции заказа списано с конкретной партии.
+type OrderItemBatch struct {
+	ID          uint        `gorm:"primaryKey"`
+	OrderItemID uint        `gorm:"index;not null"`
+	BatchID     uint        `gorm:"index;not null"`
+	Batch       *StockBatch `gorm:"constraint:OnDelete:RESTRICT;"`
+	LotNumber   string      `gorm:"type:varchar(64);not null"`
+	ExpiresAt   *time.Time  `gorm:"type:date"`
+	Quantity    int         `gorm:"not null"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type StockMovementType string
+
+const (
+	StockMovementReceipt    StockMovementType = "receipt"
+	StockMovementSale       StockMovementType = "sale"
+	StockMovementReturn     StockMovementType = "return"
+	StockMovementWriteOff   StockMovementType = "write_off"
+	StockMovementAdjustment StockMovementType = "adjustment"
+	StockMovementTransfer   StockMovementType = "transfer"
+)
+
+// Типы документов, на которые ссылается движение (помимо заказа).
+const (
+	StockDocumentTransfer      = "stock_transfer"
+	StockDocumentPurchaseOrder = "purchase_order"
+	StockDocumentStocktake     = "stocktake"
+)
+
+// StockMovement - запись журнала движения товара по партии. Журнал только
+// дополняется: остаток партии (StockBatch.Quantity) равен сумме Delta её движений.
+type StockMovement struct {
+	ID          uint              `gorm:"primaryKey"`
+	CreatedAt   time.Time         `gorm:"index"`
+	BatchID     uint              `gorm:"index;not null"`
+	VariantID   uint              `gorm:"index;not null"`
+	MedicineID  uint              `gorm:"index;not null"`
+	WarehouseID uint              `gorm:"index;not null"`
+	Type        StockMovementType `gorm:"type:varchar(16);not null"`
+	// Delta - изменение остатка партии: приход положительный, расход отрицательный.
+	Delta   int    `gorm:"not null"`
+	Reason  string `gorm:"type:varchar(255)"`
+	ActorID *uint
+	OrderID *uint `gorm:"index"`
+	// DocumentType и DocumentID - документ-основание: перемещение, поступление и т.п.
+	DocumentType string `gorm:"type:varchar(32)"`
+	DocumentID   *uint
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StocktakeStatus string
+
+const (
+	StocktakeOpen     StocktakeStatus = "open"
+	StocktakeApplied  StocktakeStatus = "applied"
+	StocktakeCanceled StocktakeStatus = "canceled"
+)
+
+// Stocktake - инвентаризация филиала. При открытии снимаются ожидаемые остатки
+// партий, затем вносятся подсчитанные количества, а при проведении расхождения
+// записываются в журнал движений корректировками.
+type Stocktake struct {
+	gorm.Model
+	WarehouseID uint            `gorm:"index;not null"`
+	Warehouse   *Warehouse      `gorm:"constraint:OnDelete:RESTRICT;"`
+	Status      StocktakeStatus `gorm:"type:varchar(16);not null;index"`
+	Comment     string          `gorm:"type:varchar(255)"`
+	CreatedBy   *uint
+	AppliedBy   *uint
+	AppliedAt   *time.Time
+
+	Lines []StocktakeLine `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// StocktakeLine - партия в инвентаризации. ExpectedQuantity - остаток партии
+// на момент открытия, CountedQuantity - фактический, nil пока не подсчитан.
+// RecallID копируется из партии при открытии.
+type StocktakeLine struct {
+	ID               uint        `gorm:"primaryKey"`
+	StocktakeID      uint        `gorm:"index;not null"`
+	BatchID          uint        `gorm:"index;not null"`
+	Batch            *StockBatch `gorm:"constraint:OnDelete:RESTRICT;"`
+	VariantID        uint        `gorm:"not null"`
+	MedicineID       uint        `gorm:"index;not null"`
+	Medicine         *Medicine   `gorm:"constraint:OnDelete:RESTRICT;"`
+	LotNumber        string      `gorm:"type:varchar(64);not null"`
+	ExpiresAt        *time.Time  `gorm:"type:date"`
+	RecallID         *uint       `gorm:"index"`
+	ExpectedQuantity int         `gorm:"not null"`
+	CountedQuantity  *int
+	CountedBy        *uint
+	CountedAt        *time.Time
+	// Adjustment - изменение остатка партии, записанное при проведении.
+	Adjustment int `gorm:"not null;default:0"`
+}
+
+// IsSellableAt - партия строки не отозвана и не просрочена, как StockBatch.IsSellableAt.
+func (l StocktakeLine) IsSellableAt(t time.Time) bool {
+	return l.RecallID == nil && (l.ExpiresAt == nil || l.ExpiresAt.After(t))
+}
+
+// Variance - расхождение фактического остатка с ожидаемым, nil пока строка не подсчитана.
+func (l StocktakeLine) Variance() *int {
+	if l.CountedQuantity == nil {
+		return nil
+	}
+	variance := *l.CountedQuantity - l.ExpectedQuantity
+	return &variance
+}
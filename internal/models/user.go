@@ -8,4 +8,6 @@ type User struct {
 	Email          string `json:"email" gorm:"type:varchar(255);uniqueIndex;not null"`
 	Phone          string `json:"phone" gorm:"type:varchar(20);uniqueIndex"`
 	DefaultAddress string `json:"default_address" gorm:"type:varchar(255);not null"`
+	PasswordHash   string `json:"-" gorm:"type:varchar(255)"`
+	Role           Role   `json:"role" gorm:"type:varchar(16);not null;default:customer"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultWarehouseCode - филиал, в который переносятся остатки, заведённые
+// до появления нескольких филиалов.
+const DefaultWarehouseCode = "MAIN"
+
+// Warehouse - аптека-филиал или склад. Остатки филиала - его партии (StockBatch).
+type Warehouse struct {
+	gorm.Model
+	Code    string `json:"code" gorm:"type:varchar(32);uniqueIndex;not null"`
+	Name    string `json:"name" gorm:"type:varchar(255);not null"`
+	City    string `json:"city" gorm:"type:varchar(100);not null;index"`
+	Address string `json:"address" gorm:"type:varchar(255);not null"`
+	// Priority - при прочих равных заказ собирается в филиале с меньшим значением.
+	Priority int  `json:"priority" gorm:"not null;default:0"`
+	IsActive bool `json:"is_active" gorm:"not null;default:true"`
+}
+
+// StockTransfer - перемещение товара одной фасовки между филиалами.
+// Партии списываются у отправителя по FEFO и с теми же сериями и сроками
+// появляются у получателя.
+type StockTransfer struct {
+	gorm.Model
+	FromWarehouseID uint       `gorm:"index;not null"`
+	FromWarehouse   *Warehouse `gorm:"constraint:OnDelete:RESTRICT;"`
+	ToWarehouseID   uint       `gorm:"index;not null"`
+	ToWarehouse     *Warehouse `gorm:"constraint:OnDelete:RESTRICT;"`
+	VariantID       uint       `gorm:"index;not null"`
+	MedicineID      uint       `gorm:"index;not null"`
+	Quantity        uint       `gorm:"not null"`
+	Comment         string     `gorm:"type:varchar(255)"`
+	CreatedBy       *uint
+
+	Lines []StockTransferLine `gorm:"foreignKey:TransferID;constraint:OnDelete:CASCADE;"`
+}
+
+type StockTransferLine struct {
+	ID          uint       `gorm:"primaryKey"`
+	TransferID  uint       `gorm:"index;not null"`
+	FromBatchID uint       `gorm:"not null"`
+	ToBatchID   uint       `gorm:"not null"`
+	LotNumber   string     `gorm:"type:varchar(64);not null"`
+	ExpiresAt   *time.Time `gorm:"type:date"`
+	Quantity    uint       `gorm:"not null"`
+}
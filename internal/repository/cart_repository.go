@@ -6,16 +6,18 @@ import (
 	"team-pharmacy/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CartRepository interface {
 	GetOrCreate(userID uint) (*models.Cart, error)
 	GetCartWithItems(userID uint) (*models.Cart, error)
 
-	GetItem(cartID uint, medicineID uint) (*models.CartItem, error)
+	GetItem(cartID uint, variantID uint) (*models.CartItem, error)
 	CreateItem(item *models.CartItem) error
 	UpdateItem(item *models.CartItem) error
 	DeleteItem(itemID uint) error
+	SwapItem(item *models.CartItem, variant *models.MedicineVariant) error
 
 	ClearCart(userID uint) error
 }
@@ -65,7 +67,7 @@ func (r *gormCartRepository) GetCartWithItems(userID uint) (*models.Cart, error)
 	)
 	var cart models.Cart
 
-	if err := r.db.Preload("Items.Medicine").Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	if err := r.db.Preload("Items.Medicine").Preload("Items.Variant").Where("user_id = ?", userID).First(&cart).Error; err != nil {
 		r.logger.Error(op,
 			"user_id", userID,
 			"error", err,
@@ -136,17 +138,17 @@ func (r *gormCartRepository) ClearCart(userID uint) error {
 	return nil
 }
 
-func (r *gormCartRepository) GetItem(cartID uint, medicineID uint) (*models.CartItem, error) {
+func (r *gormCartRepository) GetItem(cartID uint, variantID uint) (*models.CartItem, error) {
 	const op = "repo.cart_item.get"
 
 	r.logger.Debug(op,
 		"cart_id", cartID,
-		"medicine_id", medicineID,
+		"variant_id", variantID,
 	)
 	var item models.CartItem
 
 	err := r.db.
-		Where("cart_id = ? AND medicine_id = ?", cartID, medicineID).
+		Where("cart_id = ? AND variant_id = ?", cartID, variantID).
 		First(&item).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -155,10 +157,55 @@ func (r *gormCartRepository) GetItem(cartID uint, medicineID uint) (*models.Cart
 	if err != nil {
 		r.logger.Error(op,
 			"cart_id", cartID,
-			"medicine_id", medicineID,
+			"variant_id", variantID,
 			"error", err,
 		)
 		return nil, err
 	}
 	return &item, nil
 }
+
+// SwapItem заменяет вариант лекарства в позиции корзины. Если в корзине уже есть
+// позиция с этим вариантом, количество переносится в неё, а старая удаляется.
+func (r *gormCartRepository) SwapItem(item *models.CartItem, variant *models.MedicineVariant) error {
+	const op = "repo.cart_item.swap"
+
+	r.logger.Debug(op,
+		"item_id", item.ID,
+		"variant_id", variant.ID,
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.CartItem
+		err := tx.Where("cart_id = ? AND variant_id = ?", item.CartID, variant.ID).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err == nil {
+			existing.Quantity += item.Quantity
+			existing.PricePerUnit = int64(variant.Price)
+			if err := tx.Omit(clause.Associations).Save(&existing).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&models.CartItem{}, item.ID).Error
+		}
+
+		item.MedicineID = variant.MedicineID
+		item.Medicine = nil
+		item.VariantID = &variant.ID
+		item.Variant = nil
+		item.PricePerUnit = int64(variant.Price)
+		item.RecallID = nil
+		return tx.Omit(clause.Associations).Save(item).Error
+	})
+	if err != nil {
+		r.logger.Error(op,
+			"item_id", item.ID,
+			"variant_id", variant.ID,
+			"error", err,
+		)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeliverySlotRepository interface {
+	Create(slots []models.DeliverySlot) error
+	GetByID(id uint) (*models.DeliverySlot, error)
+	// ListAvailable возвращает активные слоты зоны со свободными местами,
+	// начинающиеся в интервале [from, to).
+	ListAvailable(zoneID uint, from, to time.Time) ([]models.DeliverySlot, error)
+	// Update меняет вместимость и активность слота; занятые места не трогаются.
+	Update(slot *models.DeliverySlot) error
+}
+
+type gormDeliverySlotRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliverySlotRepository(db *gorm.DB) DeliverySlotRepository {
+	return &gormDeliverySlotRepository{db: db}
+}
+
+func (r *gormDeliverySlotRepository) Create(slots []models.DeliverySlot) error {
+	return r.db.Create(&slots).Error
+}
+
+func (r *gormDeliverySlotRepository) GetByID(id uint) (*models.DeliverySlot, error) {
+	var slot models.DeliverySlot
+	if err := r.db.First(&slot, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrDeliverySlotNotFound
+		}
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (r *gormDeliverySlotRepository) ListAvailable(zoneID uint, from, to time.Time) ([]models.DeliverySlot, error) {
+	var slots []models.DeliverySlot
+	if err := r.db.Where("zone_id = ? AND is_active = ? AND reserved < capacity", zoneID, true).
+		Where("starts_at >= ? AND starts_at < ?", from, to).
+		Order("starts_at, id").
+		Find(&slots).Error; err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+func (r *gormDeliverySlotRepository) Update(slot *models.DeliverySlot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// reserved меняется только заказами, поэтому перечитываем его под блокировкой
+		var current models.DeliverySlot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, slot.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrDeliverySlotNotFound
+			}
+			return err
+		}
+		slot.Reserved = current.Reserved
+		return tx.Model(slot).Select("capacity", "is_active").Updates(slot).Error
+	})
+}
+
+// reserveDeliverySlot занимает место в слоте, если он активен, ещё не начался и не заполнен.
+func reserveDeliverySlot(tx *gorm.DB, slotID uint, now time.Time) error {
+	res := tx.Model(&models.DeliverySlot{}).
+		Where("id = ? AND is_active = ? AND starts_at > ? AND reserved < capacity", slotID, true, now).
+		UpdateColumn("reserved", gorm.Expr("reserved + 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errs.ErrDeliverySlotUnavailable
+	}
+	return nil
+}
+
+func releaseDeliverySlot(tx *gorm.DB, slotID uint) error {
+	return tx.Model(&models.DeliverySlot{}).
+		Where("id = ? AND reserved > 0", slotID).
+		UpdateColumn("reserved", gorm.Expr("reserved - 1")).Error
+}
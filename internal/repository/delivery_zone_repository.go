@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DeliveryZoneRepository interface {
+	Create(zone *models.DeliveryZone) error
+	GetByID(id uint) (*models.DeliveryZone, error)
+	// List возвращает зоны в порядке проверки: по приоритету, затем по id.
+	List(onlyActive bool) ([]models.DeliveryZone, error)
+	Update(zone *models.DeliveryZone) error
+}
+
+type gormDeliveryZoneRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryZoneRepository(db *gorm.DB) DeliveryZoneRepository {
+	return &gormDeliveryZoneRepository{db: db}
+}
+
+func (r *gormDeliveryZoneRepository) Create(zone *models.DeliveryZone) error {
+	return r.db.Create(zone).Error
+}
+
+func (r *gormDeliveryZoneRepository) GetByID(id uint) (*models.DeliveryZone, error) {
+	var zone models.DeliveryZone
+	if err := r.db.First(&zone, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrDeliveryZoneNotFound
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *gormDeliveryZoneRepository) List(onlyActive bool) ([]models.DeliveryZone, error) {
+	db := r.db
+	if onlyActive {
+		db = db.Where("is_active = ?", true)
+	}
+
+	var zones []models.DeliveryZone
+	if err := db.Order("priority, id").Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+func (r *gormDeliveryZoneRepository) Update(zone *models.DeliveryZone) error {
+	return r.db.Save(zone).Error
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DrugInteractionRepository interface {
+	// Upsert добавляет взаимодействия, а для уже известных пар обновляет
+	// тяжесть и описание.
+	Upsert(interactions []models.DrugInteraction) error
+	List() ([]models.DrugInteraction, error)
+	// ListAmong возвращает взаимодействия, в которых оба вещества из ingredientIDs.
+	ListAmong(ingredientIDs []uint) ([]models.DrugInteraction, error)
+}
+
+type gormDrugInteractionRepository struct {
+	db *gorm.DB
+}
+
+func NewDrugInteractionRepository(db *gorm.DB) DrugInteractionRepository {
+	return &gormDrugInteractionRepository{db: db}
+}
+
+func (r *gormDrugInteractionRepository) Upsert(interactions []models.DrugInteraction) error {
+	if len(interactions) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ingredient_a_id"}, {Name: "ingredient_b_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"severity", "description", "updated_at"}),
+	}).CreateInBatches(&interactions, 500).Error
+}
+
+func (r *gormDrugInteractionRepository) List() ([]models.DrugInteraction, error) {
+	var interactions []models.DrugInteraction
+	if err := r.db.Preload("IngredientA").Preload("IngredientB").
+		Order("id").Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+func (r *gormDrugInteractionRepository) ListAmong(ingredientIDs []uint) ([]models.DrugInteraction, error) {
+	var interactions []models.DrugInteraction
+	if len(ingredientIDs) < 2 {
+		return interactions, nil
+	}
+	if err := r.db.Preload("IngredientA").Preload("IngredientB").
+		Where("ingredient_a_id IN ? AND ingredient_b_id IN ?", ingredientIDs, ingredientIDs).
+		Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActiveIngredientRepository interface {
+	// EnsureByINN возвращает действующие вещества по МНН, создавая отсутствующие.
+	EnsureByINN(inns []string) (map[string]models.ActiveIngredient, error)
+	List() ([]models.ActiveIngredient, error)
+}
+
+type gormActiveIngredientRepository struct {
+	db *gorm.DB
+}
+
+func NewActiveIngredientRepository(db *gorm.DB) ActiveIngredientRepository {
+	return &gormActiveIngredientRepository{db: db}
+}
+
+func (r *gormActiveIngredientRepository) EnsureByINN(inns []string) (map[string]models.ActiveIngredient, error) {
+	result := make(map[string]models.ActiveIngredient, len(inns))
+	if len(inns) == 0 {
+		return result, nil
+	}
+
+	rows := make([]models.ActiveIngredient, 0, len(inns))
+	for _, inn := range inns {
+		rows = append(rows, models.ActiveIngredient{INN: inn})
+	}
+	if err := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "inn"}}, DoNothing: true}).
+		Create(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var ingredients []models.ActiveIngredient
+	if err := r.db.Where("inn IN ?", inns).Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	for _, ingredient := range ingredients {
+		result[ingredient.INN] = ingredient
+	}
+	return result, nil
+}
+
+func (r *gormActiveIngredientRepository) List() ([]models.ActiveIngredient, error) {
+	var ingredients []models.ActiveIngredient
+	if err := r.db.Order("inn").Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
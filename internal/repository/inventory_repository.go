@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// LowStockItem - лекарство с остатком не выше точки заказа и его продажи за период.
+type LowStockItem struct {
+	MedicineID        uint
+	Name              string
+	StockQuantity     uint
+	ReorderPoint      uint
+	SoldQuantity      int64
+	LowStockAlertedAt *time.Time
+}
+
+type InventoryRepository interface {
+	// LowStock возвращает заканчивающиеся лекарства, начиная с самых дефицитных;
+	// SoldQuantity считается по неотменённым заказам с soldSince.
+	LowStock(soldSince time.Time) ([]LowStockItem, error)
+	// PendingLowStockAlerts - то же, но только те, о которых ещё не оповещали.
+	PendingLowStockAlerts(soldSince time.Time) ([]LowStockItem, error)
+	MarkLowStockAlerted(medicineID uint, at time.Time) error
+	// ResetRecoveredAlerts снимает отметку об оповещении с лекарств, остаток
+	// которых снова выше точки заказа, чтобы при следующем падении оповестить снова.
+	ResetRecoveredAlerts() (int64, error)
+}
+
+type gormInventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &gormInventoryRepository{db: db}
+}
+
+func (r *gormInventoryRepository) LowStock(soldSince time.Time) ([]LowStockItem, error) {
+	return r.lowStock(soldSince, false)
+}
+
+func (r *gormInventoryRepository) PendingLowStockAlerts(soldSince time.Time) ([]LowStockItem, error) {
+	return r.lowStock(soldSince, true)
+}
+
+func (r *gormInventoryRepository) lowStock(soldSince time.Time, onlyPending bool) ([]LowStockItem, error) {
+	sold := r.db.Table("order_items").
+		Select("order_items.medicine_id, SUM(order_items.quantity) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.deleted_at IS NULL AND orders.deleted_at IS NULL").
+		Where("orders.status <> ? AND orders.created_at >= ?", models.OrderStatusCanceled, soldSince).
+		Group("order_items.medicine_id")
+
+	db := r.db.Model(&models.Medicine{}).
+		Select(`medicines.id AS medicine_id, medicines.name, medicines.stock_quantity, medicines.reorder_point,
+			medicines.low_stock_alerted_at, COALESCE(sold.quantity, 0) AS sold_quantity`).
+		Joins("LEFT JOIN (?) AS sold ON sold.medicine_id = medicines.id", sold).
+		Where("medicines.reorder_point > 0 AND medicines.stock_quantity <= medicines.reorder_point")
+	if onlyPending {
+		db = db.Where("medicines.low_stock_alerted_at IS NULL")
+	}
+
+	var items []LowStockItem
+	if err := db.Order("medicines.stock_quantity::float / medicines.reorder_point, medicines.id").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *gormInventoryRepository) MarkLowStockAlerted(medicineID uint, at time.Time) error {
+	return r.db.Model(&models.Medicine{}).
+		Where("id = ? AND low_stock_alerted_at IS NULL", medicineID).
+		UpdateColumn("low_stock_alerted_at", at).Error
+}
+
+func (r *gormInventoryRepository) ResetRecoveredAlerts() (int64, error) {
+	result := r.db.Model(&models.Medicine{}).
+		Where("low_stock_alerted_at IS NOT NULL AND (reorder_point = 0 OR stock_quantity > reorder_point)").
+		UpdateColumn("low_stock_alerted_at", nil)
+	return result.RowsAffected, result.Error
+}
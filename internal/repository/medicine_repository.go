@@ -1,16 +1,85 @@
 package repository
 
 import (
+	"strings"
 	"team-pharmacy/internal/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+type MedicineSort string
+
+const (
+	MedicineSortNewest    MedicineSort = "newest"
+	MedicineSortPriceAsc  MedicineSort = "price_asc"
+	MedicineSortPriceDesc MedicineSort = "price_desc"
+	MedicineSortRating    MedicineSort = "rating"
+	MedicineSortName      MedicineSort = "name"
+)
+
+// medicineSortColumns - колонка сортировки и направление. id добавляется вторым
+// ключом, чтобы порядок был стабильным и по нему работал курсор.
+var medicineSortColumns = map[MedicineSort]struct {
+	column string
+	desc   bool
+}{
+	MedicineSortNewest:    {"created_at", true},
+	MedicineSortPriceAsc:  {"price", false},
+	MedicineSortPriceDesc: {"price", true},
+	MedicineSortRating:    {"avg_rating", true},
+	MedicineSortName:      {"name", false},
+}
+
+// MedicineFilter - параметры выборки каталога. Нулевые значения не фильтруют.
+type MedicineFilter struct {
+	Query                string
+	CategoryID           *uint
+	SubcategoryID        *uint
+	Manufacturer         string
+	PriceMin             *uint64
+	PriceMax             *uint64
+	InStock              *bool
+	PrescriptionRequired *bool
+	MinRating            *float64
+	// WarehouseID - только лекарства, которые есть в продаже в этом филиале.
+	WarehouseID *uint
+
+	Sort MedicineSort
+	// AfterID - id последнего элемента предыдущей страницы (курсор).
+	// Если задан, Offset игнорируется.
+	AfterID uint
+	Offset  int
+	Limit   int
+}
+
+type CategoryFacet struct {
+	CategoryID uint
+	Name       string
+	Count      int64
+}
+
+type ManufacturerFacet struct {
+	Manufacturer string
+	Count        int64
+}
+
+type MedicineFacets struct {
+	Categories    []CategoryFacet
+	Manufacturers []ManufacturerFacet
+}
+
 type MedicineRepository interface {
 	Create(medicine *models.Medicine) error
-	GetAll() ([]models.Medicine, error)
+	List(filter MedicineFilter) ([]models.Medicine, int64, error)
+	Facets(filter MedicineFilter) (*MedicineFacets, error)
+	Search(query string, limit, offset int) (*MedicineSearchResult, error)
 	GetByID(id uint) (*models.Medicine, error)
-	Update(medicine *models.Medicine) error
+	Analogs(medicineID uint) ([]models.Medicine, error)
+	IngredientsOf(medicineIDs []uint) ([]models.MedicineIngredient, error)
+	// Update сохраняет лекарство вместе с изменёнными вариантами в одной транзакции.
+	Update(medicine *models.Medicine, variants ...*models.MedicineVariant) error
 	Delete(id uint) error
 	UpdateAvgRating(medicineId uint, avg float64) error
 }
@@ -22,27 +91,232 @@ func NewMedicineRepository(db *gorm.DB) MedicineRepository {
 	return &MedicineRepo{db: db}
 }
 func (m *MedicineRepo) Create(medicine *models.Medicine) error {
-	return m.db.Create(medicine).Error
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Variants", "Ingredients.ActiveIngredient").Create(medicine).Error; err != nil {
+			return err
+		}
+		if len(medicine.Variants) > 0 {
+			for i := range medicine.Variants {
+				medicine.Variants[i].MedicineID = medicine.ID
+				if medicine.Variants[i].SKU == "" {
+					medicine.Variants[i].SKU = defaultSKU(medicine.ID, i+1)
+				}
+			}
+			if err := tx.Omit(clause.Associations).Create(&medicine.Variants).Error; err != nil {
+				return err
+			}
+			if err := syncMedicineStock(tx, medicine.ID); err != nil {
+				return err
+			}
+		}
+		return refreshSearchVector(tx, medicine.ID)
+	})
 }
-func (m *MedicineRepo) GetAll() ([]models.Medicine, error) {
+
+// List возвращает страницу каталога и общее число подходящих под фильтр лекарств.
+func (m *MedicineRepo) List(filter MedicineFilter) ([]models.Medicine, int64, error) {
+	var total int64
+	if err := applyMedicineFilter(m.db.Model(&models.Medicine{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sort, ok := medicineSortColumns[filter.Sort]
+	if !ok {
+		sort = medicineSortColumns[MedicineSortNewest]
+	}
+	direction, cmp := "ASC", ">"
+	if sort.desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	query := applyMedicineFilter(m.db.Model(&models.Medicine{}), filter)
+	if filter.AfterID != 0 {
+		query = query.Where("(medicines."+sort.column+", medicines.id) "+cmp+
+			" (SELECT "+sort.column+", id FROM medicines WHERE id = ?)", filter.AfterID)
+	} else if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
 	var medicines []models.Medicine
-	err := m.db.Find(&medicines).Error
+	err := query.Order("medicines." + sort.column + " " + direction).Order("medicines.id " + direction).
+		Limit(filter.Limit).Find(&medicines).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return medicines, total, nil
+}
+
+// Facets считает количество лекарств по категориям и производителям.
+// Фасет не учитывает фильтр по собственному измерению, чтобы клиент видел,
+// сколько товаров появится при выборе другого значения.
+func (m *MedicineRepo) Facets(filter MedicineFilter) (*MedicineFacets, error) {
+	facets := &MedicineFacets{}
+
+	byCategory := filter
+	byCategory.CategoryID = nil
+	byCategory.SubcategoryID = nil
+	err := applyMedicineFilter(m.db.Model(&models.Medicine{}), byCategory).
+		Select("medicines.category_id, categories.name, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = medicines.category_id AND categories.deleted_at IS NULL").
+		Group("medicines.category_id, categories.name").
+		Order("count DESC, categories.name").
+		Scan(&facets.Categories).Error
 	if err != nil {
 		return nil, err
 	}
-	return medicines, nil
+
+	byManufacturer := filter
+	byManufacturer.Manufacturer = ""
+	err = applyMedicineFilter(m.db.Model(&models.Medicine{}), byManufacturer).
+		Select("manufacturer, COUNT(*) AS count").
+		Group("manufacturer").
+		Order("count DESC, manufacturer").
+		Scan(&facets.Manufacturers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func applyMedicineFilter(db *gorm.DB, filter MedicineFilter) *gorm.DB {
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		db = db.Where("(medicines.name ILIKE ? OR medicines.manufacturer ILIKE ? OR medicines.description ILIKE ?)",
+			pattern, pattern, pattern)
+	}
+	if filter.CategoryID != nil {
+		db = db.Where("medicines.category_id = ?", *filter.CategoryID)
+	}
+	if filter.SubcategoryID != nil {
+		db = db.Where("medicines.subcategory_id = ?", *filter.SubcategoryID)
+	}
+	if filter.Manufacturer != "" {
+		db = db.Where("medicines.manufacturer = ?", filter.Manufacturer)
+	}
+	if filter.PriceMin != nil {
+		db = db.Where("medicines.price >= ?", *filter.PriceMin)
+	}
+	if filter.PriceMax != nil {
+		db = db.Where("medicines.price <= ?", *filter.PriceMax)
+	}
+	if filter.InStock != nil {
+		db = db.Where("medicines.in_stock = ?", *filter.InStock)
+	}
+	if filter.PrescriptionRequired != nil {
+		db = db.Where("medicines.prescription_required = ?", *filter.PrescriptionRequired)
+	}
+	if filter.MinRating != nil {
+		db = db.Where("medicines.avg_rating >= ?", *filter.MinRating)
+	}
+	if filter.WarehouseID != nil {
+		db = db.Where(`EXISTS (SELECT 1 FROM stock_batches
+			WHERE stock_batches.medicine_id = medicines.id AND warehouse_id = ? AND quantity > 0
+				AND deleted_at IS NULL AND `+sellableBatchCondition+`)`, *filter.WarehouseID, time.Now())
+	}
+	return db
 }
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 func (m *MedicineRepo) GetByID(id uint) (*models.Medicine, error) {
 	medicine := models.Medicine{}
-	err := m.db.First(&medicine, id).Error
+	err := m.db.Preload("Ingredients.ActiveIngredient").Preload("Variants", orderVariants).
+		First(&medicine, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &medicine, nil
 }
-func (m *MedicineRepo) Update(medicine *models.Medicine) error {
-	return m.db.Save(medicine).Error
+
+// Analogs возвращает лекарства в наличии с тем же набором действующих веществ
+// в той же дозировке, что и у medicineID, от дешёвых к дорогим.
+func (m *MedicineRepo) Analogs(medicineID uint) ([]models.Medicine, error) {
+	var ingredientsCount int64
+	if err := m.db.Model(&models.MedicineIngredient{}).Where("medicine_id = ?", medicineID).
+		Count(&ingredientsCount).Error; err != nil {
+		return nil, err
+	}
+	if ingredientsCount == 0 {
+		return []models.Medicine{}, nil
+	}
+
+	// совпадают все вещества исходного лекарства и у аналога нет лишних
+	matching := m.db.Table("medicine_ingredients AS mi").
+		Select("mi.medicine_id").
+		Joins(`JOIN medicine_ingredients src ON src.medicine_id = ?
+			AND src.active_ingredient_id = mi.active_ingredient_id
+			AND src.strength = mi.strength
+			AND src.strength_unit = mi.strength_unit`, medicineID).
+		Where("mi.medicine_id <> ?", medicineID).
+		Group("mi.medicine_id").
+		Having("COUNT(*) = ?", ingredientsCount).
+		Having("COUNT(*) = (SELECT COUNT(*) FROM medicine_ingredients x WHERE x.medicine_id = mi.medicine_id)")
+
+	var medicines []models.Medicine
+	err := m.db.Preload("Ingredients.ActiveIngredient").Preload("Variants", orderVariants).
+		Where("id IN (?) AND in_stock", matching).
+		Order("price, id").
+		Find(&medicines).Error
+	if err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+func (m *MedicineRepo) Update(medicine *models.Medicine, variants ...*models.MedicineVariant) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		// цена и остаток считаются по вариантам, связи сохраняются отдельно,
+		// отметку об оповещении ведёт только проверка остатков
+		if err := tx.Omit(clause.Associations, "LowStockAlertedAt").Save(medicine).Error; err != nil {
+			return err
+		}
+		for _, variant := range variants {
+			if err := saveVariant(tx, variant); err != nil {
+				return err
+			}
+		}
+		if err := syncMedicineStock(tx, medicine.ID); err != nil {
+			return err
+		}
+		if medicine.Ingredients != nil {
+			if err := replaceIngredients(tx, medicine); err != nil {
+				return err
+			}
+		}
+		return refreshSearchVector(tx, medicine.ID)
+	})
+}
+func (m *MedicineRepo) IngredientsOf(medicineIDs []uint) ([]models.MedicineIngredient, error) {
+	var ingredients []models.MedicineIngredient
+	if len(medicineIDs) == 0 {
+		return ingredients, nil
+	}
+	if err := m.db.Where("medicine_id IN ?", medicineIDs).Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func orderVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("price, id")
 }
+
+func replaceIngredients(tx *gorm.DB, medicine *models.Medicine) error {
+	if err := tx.Where("medicine_id = ?", medicine.ID).Delete(&models.MedicineIngredient{}).Error; err != nil {
+		return err
+	}
+	if len(medicine.Ingredients) == 0 {
+		return nil
+	}
+	for i := range medicine.Ingredients {
+		medicine.Ingredients[i].ID = 0
+		medicine.Ingredients[i].MedicineID = medicine.ID
+	}
+	return tx.Omit("ActiveIngredient").Create(&medicine.Ingredients).Error
+}
+
 func (m *MedicineRepo) Delete(id uint) error {
 	return m.db.Delete(&models.Medicine{}, id).Error
 }
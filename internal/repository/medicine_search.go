@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"team-pharmacy/internal/models"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Поиск по каталогу: полнотекстовый по tsvector с русской морфологией,
+// а если он ничего не нашёл - нечёткий по триграммам (опечатки в названии
+// или производителе).
+
+const searchVectorExpr = `setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce(manufacturer, '')), 'B') ||
+	setweight(to_tsvector('russian', coalesce(description, '')), 'C')`
+
+// fuzzySearchThreshold - минимальная word_similarity для нечёткого совпадения.
+const fuzzySearchThreshold = 0.3
+
+// Совпадения ts_headline отмечаются символами из области частного использования,
+// а не тегами: текст каталога сначала экранируется как HTML, и только потом
+// маркеры заменяются на <mark> (escapeHighlight).
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+
+	nameHeadlineOptions = `HighlightAll=true, StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+	headlineOptions     = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxWords=25, MinWords=8, MaxFragments=2`
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+type MedicineSearchMode string
+
+const (
+	MedicineSearchFullText MedicineSearchMode = "fulltext"
+	MedicineSearchFuzzy    MedicineSearchMode = "fuzzy"
+)
+
+type MedicineSearchHit struct {
+	Medicine      models.Medicine
+	Rank          float64
+	NameHighlight string
+	Snippet       string
+}
+
+type MedicineSearchResult struct {
+	Mode  MedicineSearchMode
+	Total int64
+	Hits  []MedicineSearchHit
+}
+
+type searchRow struct {
+	ID            uint
+	Rank          float64
+	NameHighlight string
+	Snippet       string
+}
+
+// MigrateMedicineSearch создаёт расширение pg_trgm, GIN-индексы для поиска
+// и заполняет search_vector у записей, добавленных до его появления.
+// Вызывается после AutoMigrate.
+func MigrateMedicineSearch(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_medicines_search_vector ON medicines USING gin (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_medicines_name_trgm ON medicines USING gin (name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_medicines_manufacturer_trgm ON medicines USING gin (manufacturer gin_trgm_ops)`,
+		`UPDATE medicines SET search_vector = ` + searchVectorExpr + ` WHERE search_vector IS NULL`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func refreshSearchVector(tx *gorm.DB, medicineID uint) error {
+	return tx.Exec(`UPDATE medicines SET search_vector = `+searchVectorExpr+` WHERE id = ?`, medicineID).Error
+}
+
+func (m *MedicineRepo) Search(query string, limit, offset int) (*MedicineSearchResult, error) {
+	result := &MedicineSearchResult{Mode: MedicineSearchFullText}
+
+	if tsQuery := prefixTSQuery(query); tsQuery != "" {
+		err := m.db.Raw(`SELECT COUNT(*) FROM medicines
+			WHERE deleted_at IS NULL AND search_vector @@ to_tsquery('russian', ?)`, tsQuery).
+			Scan(&result.Total).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Total > 0 {
+			var rows []searchRow
+			err = m.db.Raw(`SELECT m.id,
+					ts_rank_cd(m.search_vector, q) AS rank,
+					ts_headline('russian', m.name, q, ?) AS name_highlight,
+					ts_headline('russian', coalesce(m.description, ''), q, ?) AS snippet
+				FROM medicines m, to_tsquery('russian', ?) q
+				WHERE m.deleted_at IS NULL AND m.search_vector @@ q
+				ORDER BY rank DESC, m.id
+				LIMIT ? OFFSET ?`, nameHeadlineOptions, headlineOptions, tsQuery, limit, offset).
+				Scan(&rows).Error
+			if err != nil {
+				return nil, err
+			}
+			return m.fillSearchHits(result, rows)
+		}
+	}
+
+	result.Mode = MedicineSearchFuzzy
+	var rows []searchRow
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		// порог для оператора <%, чтобы запрос шёл по триграммному индексу
+		if err := tx.Exec(`SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)`,
+			strconv.FormatFloat(fuzzySearchThreshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+
+		err := tx.Raw(`SELECT COUNT(*) FROM medicines
+			WHERE deleted_at IS NULL AND (? <% name OR ? <% manufacturer)`, query, query).
+			Scan(&result.Total).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Raw(`SELECT id,
+				GREATEST(word_similarity(?, name), word_similarity(?, manufacturer)) AS rank,
+				name AS name_highlight,
+				left(coalesce(description, ''), 200) AS snippet
+			FROM medicines
+			WHERE deleted_at IS NULL AND (? <% name OR ? <% manufacturer)
+			ORDER BY rank DESC, id
+			LIMIT ? OFFSET ?`, query, query, query, query, limit, offset).
+			Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.fillSearchHits(result, rows)
+}
+
+// fillSearchHits подгружает лекарства по найденным id, сохраняя порядок ранжирования.
+func (m *MedicineRepo) fillSearchHits(result *MedicineSearchResult, rows []searchRow) (*MedicineSearchResult, error) {
+	result.Hits = make([]MedicineSearchHit, 0, len(rows))
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var medicines []models.Medicine
+	if err := m.db.Where("id IN ?", ids).Find(&medicines).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Medicine, len(medicines))
+	for _, medicine := range medicines {
+		byID[medicine.ID] = medicine
+	}
+
+	for _, row := range rows {
+		medicine, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		result.Hits = append(result.Hits, MedicineSearchHit{
+			Medicine:      medicine,
+			Rank:          row.Rank,
+			NameHighlight: escapeHighlight(row.NameHighlight),
+			Snippet:       escapeHighlight(row.Snippet),
+		})
+	}
+	return result, nil
+}
+
+// escapeHighlight экранирует текст каталога для вывода как HTML и превращает
+// маркеры совпадений в теги <mark>.
+func escapeHighlight(s string) string {
+	return highlightReplacer.Replace(html.EscapeString(s))
+}
+
+// prefixTSQuery превращает пользовательский ввод в tsquery вида "аспир:* & байер:*",
+// чтобы находились и неполные слова. Всё, кроме букв и цифр, отбрасывается.
+func prefixTSQuery(input string) string {
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,46 @@
+package repository
+
+import "testing"
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"аспирин", "аспирин:*"},
+		{"Аспир Байер", "аспир:* & байер:*"},
+		{"  но-шпа  ", "но:* & шпа:*"},
+		{"витамин d3", "витамин:* & d3:*"},
+		{"a & b | !c", "a:* & b:* & c:*"},
+		{"'); DROP TABLE medicines; --", "drop:* & table:* & medicines:*"},
+		{"", ""},
+		{"!!! ???", ""},
+	}
+
+	for _, tt := range tests {
+		if got := prefixTSQuery(tt.input); got != tt.want {
+			t.Errorf("prefixTSQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeHighlight(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"без совпадений", "без совпадений"},
+		{highlightStart + "аспирин" + highlightStop + " 500 мг", "<mark>аспирин</mark> 500 мг"},
+		{
+			"<script>alert(1)</script> " + highlightStart + "аспирин" + highlightStop,
+			"&lt;script&gt;alert(1)&lt;/script&gt; <mark>аспирин</mark>",
+		},
+		{"Doctor's <b>choice</b> & co", "Doctor&#39;s &lt;b&gt;choice&lt;/b&gt; &amp; co"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeHighlight(tt.input); got != tt.want {
+			t.Errorf("escapeHighlight(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MedicineVariantRepository interface {
+	Create(variant *models.MedicineVariant) error
+	GetByID(id uint) (*models.MedicineVariant, error)
+	ListByMedicine(medicineID uint) ([]models.MedicineVariant, error)
+	Update(variant *models.MedicineVariant) error
+	Delete(variant *models.MedicineVariant) error
+}
+
+type gormMedicineVariantRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicineVariantRepository(db *gorm.DB) MedicineVariantRepository {
+	return &gormMedicineVariantRepository{db: db}
+}
+
+func (r *gormMedicineVariantRepository) Create(variant *models.MedicineVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if variant.SKU == "" {
+			var count int64
+			if err := tx.Unscoped().Model(&models.MedicineVariant{}).
+				Where("medicine_id = ?", variant.MedicineID).Count(&count).Error; err != nil {
+				return err
+			}
+			variant.SKU = defaultSKU(variant.MedicineID, int(count)+1)
+		}
+		if err := tx.Omit(clause.Associations).Create(variant).Error; err != nil {
+			return err
+		}
+		return syncMedicineStock(tx, variant.MedicineID)
+	})
+}
+
+func (r *gormMedicineVariantRepository) GetByID(id uint) (*models.MedicineVariant, error) {
+	var variant models.MedicineVariant
+	if err := r.db.First(&variant, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrVariantNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *gormMedicineVariantRepository) ListByMedicine(medicineID uint) ([]models.MedicineVariant, error) {
+	var variants []models.MedicineVariant
+	if err := r.db.Where("medicine_id = ?", medicineID).Order("price, id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *gormMedicineVariantRepository) Update(variant *models.MedicineVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVariant(tx, variant); err != nil {
+			return err
+		}
+		return syncMedicineStock(tx, variant.MedicineID)
+	})
+}
+
+// saveVariant сохраняет поля варианта. Остаток меняется только через партии,
+// поэтому здесь его не перезаписываем.
+func saveVariant(tx *gorm.DB, variant *models.MedicineVariant) error {
+	return tx.Omit(clause.Associations, "StockQuantity", "InStock").Save(variant).Error
+}
+
+// Delete удаляет вариант без остатка. Остаток в партиях сначала нужно списать
+// через журнал движений, иначе он пропал бы из каталога мимо журнала.
+func (r *gormMedicineVariantRepository) Delete(variant *models.MedicineVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVariants(tx, variant.ID); err != nil {
+			return err
+		}
+
+		var stocked int64
+		if err := tx.Model(&models.StockBatch{}).
+			Where("variant_id = ? AND quantity > 0", variant.ID).
+			Count(&stocked).Error; err != nil {
+			return err
+		}
+		if stocked > 0 {
+			return errs.ErrVariantHasStock
+		}
+
+		if err := tx.Delete(&models.MedicineVariant{}, variant.ID).Error; err != nil {
+			return err
+		}
+		return syncMedicineStock(tx, variant.MedicineID)
+	})
+}
+
+func defaultSKU(medicineID uint, n int) string {
+	return fmt.Sprintf("MED%06d-%d", medicineID, n)
+}
+
+// syncMedicineStock пересчитывает у лекарств суммарный остаток, наличие и
+// минимальную цену по их вариантам. Строки обновляются в порядке id, как и
+// при резервировании, чтобы не было взаимных блокировок.
+func syncMedicineStock(tx *gorm.DB, medicineIDs ...uint) error {
+	ids := slices.Clone(medicineIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	for _, id := range ids {
+		err := tx.Exec(`UPDATE medicines SET
+				stock_quantity = s.stock,
+				in_stock = s.stock > 0,
+				price = COALESCE(s.min_price, medicines.price),
+				updated_at = NOW()
+			FROM (SELECT COALESCE(SUM(stock_quantity), 0) AS stock, MIN(price) AS min_price
+				FROM medicine_variants WHERE medicine_id = ? AND deleted_at IS NULL) s
+			WHERE medicines.id = ?`, id, id).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateMedicineVariants создаёт вариант по умолчанию для лекарств, заведённых
+// до появления вариантов, и привязывает к нему старые позиции корзин и заказов.
+// Вызывается после AutoMigrate.
+func MigrateMedicineVariants(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO medicine_variants
+				(created_at, updated_at, medicine_id, sku, name, price, stock_quantity, in_stock)
+			SELECT NOW(), NOW(), m.id, 'MED' || lpad(m.id::text, 6, '0') || '-1', ?, m.price, m.stock_quantity, m.stock_quantity > 0
+			FROM medicines m
+			WHERE NOT EXISTS (SELECT 1 FROM medicine_variants v WHERE v.medicine_id = m.id)`,
+			models.DefaultVariantName).Error
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"cart_items", "order_items"} {
+			err := tx.Exec(`UPDATE ` + table + ` t SET variant_id = (
+					SELECT v.id FROM medicine_variants v WHERE v.medicine_id = t.medicine_id ORDER BY v.id LIMIT 1)
+				WHERE t.variant_id IS NULL`).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,19 +1,37 @@
 package repository
 
 import (
+	"cmp"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"slices"
 	"team-pharmacy/internal/errs"
 	"team-pharmacy/internal/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository interface {
 	CreateOrder(order *models.Order) error
 	GetByID(orderID uint) (*models.Order, error)
 	GetListOrders(userID uint) ([]models.Order, error)
-	UpdateOrder(orderID uint, status *models.OrderStatus) error
+	UpdateOrder(orderID uint, status *models.OrderStatus, actorID *uint, reason string) error
 	CreateOrderWithClearCart(order *models.Order, cartID uint) error
+	CancelOrder(orderID uint, canceledBy *uint, reason string, onlyFrom models.OrderStatus) (*models.Order, error)
+	UpdateRefund(refund *models.Refund) error
+	// ListFailedRefunds возвращает отклонённые провайдером возвраты вместе с платежами.
+	ListFailedRefunds() ([]models.Refund, error)
+	// ClaimFailedRefund переводит отклонённый возврат обратно в pending перед повторной
+	// попыткой. false - возврат уже забрал другой обработчик.
+	ClaimFailedRefund(refundID uint) (bool, error)
+	ListPendingCreatedBefore(before time.Time) ([]models.Order, error)
+	ListEvents(orderID uint) ([]models.OrderEvent, error)
+	// CompletePickup выдаёт готовый заказ самовывоза, если совпал код получения
+	// и пункт сейчас работает.
+	CompletePickup(orderID uint, code string, actorID *uint) error
 }
 
 type gormOrderRepository struct {
@@ -33,7 +51,9 @@ func (r *gormOrderRepository) CreateOrder(order *models.Order) error {
 func (r *gormOrderRepository) GetByID(orderID uint) (*models.Order, error) {
 	var order *models.Order
 
-	if err := r.db.Preload("Items").First(&order, orderID).Error; err != nil {
+	if err := r.db.Preload("Items.Batches").Preload("Payments").Preload("Refunds").Preload("DeliverySlot").Preload("Shipment").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&order, orderID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errs.ErrOrderNotFound
 		}
@@ -51,21 +71,507 @@ func (r *gormOrderRepository) GetListOrders(userID uint) ([]models.Order, error)
 	return list, nil
 }
 
-func (r *gormOrderRepository) UpdateOrder(orderID uint, status *models.OrderStatus) error {
+// UpdateOrder меняет статус заказа и пишет переход в журнал событий.
+func (r *gormOrderRepository) UpdateOrder(orderID uint, status *models.OrderStatus, actorID *uint, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrOrderNotFound
+			}
+			return err
+		}
+
+		if !order.CanChangeStatus(*status) {
+			return errs.ErrInvalidStatusTransition
+		}
+		// товар из отозванной серии нельзя готовить к выдаче
+		if order.RecallID != nil && *status == models.OrderStatusReadyForPickup {
+			return errs.ErrOrderRecalled
+		}
+
+		from := order.Status
+		if err := tx.Model(&order).Update("status", status).Error; err != nil {
+			return err
+		}
+
+		return recordOrderEvent(tx, order.ID, from, *status, actorID, reason)
+	})
+}
+
+func (r *gormOrderRepository) CompletePickup(orderID uint, code string, actorID *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrOrderNotFound
+			}
+			return err
+		}
 
-	return r.db.Model(&models.Order{}).Where("id = ?", orderID).Update("status", status).Error
+		if order.Status != models.OrderStatusReadyForPickup {
+			return errs.ErrOrderNotReadyForPickup
+		}
+		if order.RecallID != nil {
+			return errs.ErrOrderRecalled
+		}
+		if order.PickupCode == "" || subtle.ConstantTimeCompare([]byte(order.PickupCode), []byte(code)) != 1 {
+			return errs.ErrInvalidPickupCode
+		}
+		// выдача фиксируется только в часы работы пункта
+		if order.PickupPointID != nil {
+			var point models.PickupPoint
+			if err := tx.First(&point, *order.PickupPointID).Error; err != nil {
+				return err
+			}
+			if !point.IsOpenAt(time.Now()) {
+				return errs.ErrPickupPointClosed
+			}
+		}
 
+		if err := tx.Model(&order).Update("status", models.OrderStatusCompleted).Error; err != nil {
+			return err
+		}
+		return recordOrderEvent(tx, order.ID, models.OrderStatusReadyForPickup, models.OrderStatusCompleted,
+			actorID, "picked up")
+	})
 }
 
+func (r *gormOrderRepository) ListEvents(orderID uint) ([]models.OrderEvent, error) {
+	var list []models.OrderEvent
+
+	if err := r.db.Where("order_id = ?", orderID).Order("created_at, id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateOrderWithClearCart в одной транзакции резервирует остатки, создаёт заказ и очищает корзину.
 func (r *gormOrderRepository) CreateOrderWithClearCart(order *models.Order, cartID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if order.WarehouseID == nil {
+			return errs.ErrWarehouseNotFound
+		}
+		if err := reserveStock(tx, *order.WarehouseID, order.Items); err != nil {
+			return err
+		}
+		if order.DeliverySlotID != nil {
+			if err := reserveDeliverySlot(tx, *order.DeliverySlotID, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+
+		if err := recordOrderMovements(tx, order, models.StockMovementSale, -1, &order.UserID, "order created"); err != nil {
+			return err
+		}
+
+		if err := recordOrderEvent(tx, order.ID, "", order.Status, &order.UserID, "order created"); err != nil {
+			return err
+		}
+
+		if order.PromocodeID != nil {
+			if err := redeemPromocode(tx, order); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// CancelOrder в одной транзакции отменяет заказ, возвращает остатки на склад,
+// освобождает слот доставки и промокод и создаёт ожидающие возвраты по всем
+// успешным платежам, которые ещё не возвращались.
+// Если onlyFrom не пуст, заказ отменяется только из этого статуса.
+func (r *gormOrderRepository) CancelOrder(orderID uint, canceledBy *uint, reason string, onlyFrom models.OrderStatus) (*models.Order, error) {
+	var order models.Order
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Items.Batches").
+			Preload("Payments").
+			Preload("Refunds").
+			Preload("DeliverySlot").
+			First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrOrderNotFound
+			}
+			return err
+		}
+
+		if onlyFrom != "" && order.Status != onlyFrom {
+			return errs.ErrOrderNotCancelable
+		}
+		if !models.CanChangeOrderStatus(order.Status, models.OrderStatusCanceled) {
+			return errs.ErrOrderNotCancelable
+		}
+
+		if err := releaseStock(tx, order.Items); err != nil {
+			return err
+		}
+		if err := recordOrderMovements(tx, &order, models.StockMovementReturn, 1, canceledBy, reason); err != nil {
+			return err
+		}
+		if order.DeliverySlotID != nil {
+			if err := releaseDeliverySlot(tx, *order.DeliverySlotID); err != nil {
+				return err
+			}
+		}
+		if order.PromocodeID != nil {
+			if err := releasePromocode(tx, &order); err != nil {
+				return err
+			}
+		}
+
+		// платёж, подтверждённый, когда заказ уже был оплачен другим, возвращён ещё при подтверждении
+		refunded := make(map[uint]bool, len(order.Refunds))
+		for _, refund := range order.Refunds {
+			refunded[refund.PaymentID] = true
+		}
+
+		for _, payment := range order.Payments {
+			if payment.Status != models.StatusSuccess || refunded[payment.ID] {
+				continue
+			}
+			refund := models.Refund{
+				PaymentID: payment.ID,
+				OrderID:   order.ID,
+				Amount:    payment.Amount,
+				Status:    models.StatusPending,
+				Reason:    reason,
+			}
+			if err := tx.Create(&refund).Error; err != nil {
+				return err
+			}
+			order.Refunds = append(order.Refunds, refund)
+		}
+
+		if err := recordOrderEvent(tx, order.ID, order.Status, models.OrderStatusCanceled, canceledBy, reason); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		order.Status = models.OrderStatusCanceled
+		order.CanceledAt = &now
+		order.CanceledBy = canceledBy
+		order.CancelReason = reason
+
+		return tx.Model(&order).Updates(map[string]any{
+			"status":        order.Status,
+			"canceled_at":   order.CanceledAt,
+			"canceled_by":   order.CanceledBy,
+			"cancel_reason": order.CancelReason,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *gormOrderRepository) UpdateRefund(refund *models.Refund) error {
+	return r.db.Save(refund).Error
+}
+
+func (r *gormOrderRepository) ListFailedRefunds() ([]models.Refund, error) {
+	var list []models.Refund
+
+	if err := r.db.Preload("Payment").
+		Where("status = ?", models.StatusFailed).
+		Order("id").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormOrderRepository) ClaimFailedRefund(refundID uint) (bool, error) {
+	res := r.db.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refundID, models.StatusFailed).
+		Update("status", models.StatusPending)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *gormOrderRepository) ListPendingCreatedBefore(before time.Time) ([]models.Order, error) {
+	var list []models.Order
+
+	if err := r.db.
+		Where("status = ? AND created_at < ?", models.OrderStatusPendingPayment, before).
+		Order("created_at").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func recordOrderEvent(tx *gorm.DB, orderID uint, from, to models.OrderStatus, actorID *uint, reason string) error {
+	return tx.Create(&models.OrderEvent{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorID,
+		Reason:     reason,
+	}).Error
+}
 
-	if err := r.db.Create(order).Error; err != nil {
+// reserveStock блокирует строки вариантов (в порядке id, чтобы избежать взаимных блокировок),
+// списывает количество с продаваемых партий филиала по правилу FEFO (первой уходит партия
+// с ближайшим сроком годности) и записывает в позиции заказа, с каких партий взят товар.
+// Позиции меняются на месте, записи о партиях создаются вместе с заказом.
+func reserveStock(tx *gorm.DB, warehouseID uint, items []models.OrderItem) error {
+	required, ids, err := quantitiesByVariant(items)
+	if err != nil {
 		return err
 	}
 
-	if err := r.db.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+	variants, err := lockVariants(tx, ids...)
+	if err != nil {
 		return err
 	}
+	if len(variants) != len(ids) {
+		return errs.ErrVariantNotFound
+	}
+	for _, variant := range variants {
+		if variant.DeletedAt.Valid {
+			return errs.ErrVariantNotFound
+		}
+	}
+
+	now := time.Now()
+	var batches []models.StockBatch
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id IN ? AND warehouse_id = ? AND quantity > 0", ids, warehouseID).
+		Where(sellableBatchCondition, now).
+		Order("variant_id, expires_at NULLS LAST, id").
+		Find(&batches).Error; err != nil {
+		return err
+	}
+
+	available := make(map[uint]int, len(variants))
+	for _, batch := range batches {
+		available[batch.VariantID] += int(batch.Quantity)
+	}
+
+	for _, variant := range variants {
+		quantity := required[variant.ID]
+		if quantity <= 0 || available[variant.ID] < quantity {
+			return fmt.Errorf("%w: %s", errs.ErrInsufficientStock, variant.SKU)
+		}
+	}
+
+	allocateFEFO(items, batches)
+
+	for i := range batches {
+		if err := tx.Model(&batches[i]).Update("quantity", batches[i].Quantity).Error; err != nil {
+			return err
+		}
+	}
+	return syncVariantStock(tx, now, ids...)
+}
+
+// allocateFEFO списывает позиции заказа с партий их фасовок: первой расходуется
+// партия с ближайшим сроком годности, партии без срока (LEGACY) - последними.
+// Остатки партий уменьшаются на месте, в позиции записывается, сколько взято
+// с каждой партии. Хватает ли партий, проверяет вызывающий.
+func allocateFEFO(items []models.OrderItem, batches []models.StockBatch) {
+	byVariant := make(map[uint][]*models.StockBatch)
+	for i := range batches {
+		batch := &batches[i]
+		byVariant[batch.VariantID] = append(byVariant[batch.VariantID], batch)
+	}
+	for _, list := range byVariant {
+		slices.SortFunc(list, compareFEFO)
+	}
+
+	for i := range items {
+		item := &items[i]
+		need := item.Quantity
+		for _, batch := range byVariant[*item.VariantID] {
+			if need == 0 {
+				break
+			}
+			if batch.Quantity == 0 {
+				continue
+			}
+			take := min(need, int(batch.Quantity))
+			batch.Quantity -= uint(take)
+			need -= take
+			item.Batches = append(item.Batches, models.OrderItemBatch{
+				BatchID:   batch.ID,
+				LotNumber: batch.LotNumber,
+				ExpiresAt: batch.ExpiresAt,
+				Quantity:  take,
+			})
+		}
+	}
+}
+
+// compareFEFO упорядочивает партии по сроку годности, партии без срока - в конце.
+func compareFEFO(a, b *models.StockBatch) int {
+	switch {
+	case a.ExpiresAt == nil && b.ExpiresAt == nil:
+		return cmp.Compare(a.ID, b.ID)
+	case a.ExpiresAt == nil:
+		return 1
+	case b.ExpiresAt == nil:
+		return -1
+	}
+	if c := a.ExpiresAt.Compare(*b.ExpiresAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// releaseStock возвращает на склад количество, зарезервированное позициями заказа,
+// в те же партии, с которых оно было списано. Позиции должны быть загружены с Batches.
+func releaseStock(tx *gorm.DB, items []models.OrderItem) error {
+	_, ids, err := quantitiesByVariant(items)
+	if err != nil {
+		return err
+	}
+
+	if _, err := lockVariants(tx, ids...); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		for _, allocation := range item.Batches {
+			err := tx.Unscoped().Model(&models.StockBatch{}).
+				Where("id = ?", allocation.BatchID).
+				Update("quantity", gorm.Expr("quantity + ?", allocation.Quantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return syncVariantStock(tx, time.Now(), ids...)
+}
+
+// recordOrderMovements записывает в журнал движения по партиям позиций заказа:
+// продажу при создании (sign = -1) и возврат при отмене (sign = 1).
+func recordOrderMovements(tx *gorm.DB, order *models.Order, movementType models.StockMovementType,
+	sign int, actorID *uint, reason string) error {
+
+	var batchIDs []uint
+	for _, item := range order.Items {
+		for _, allocation := range item.Batches {
+			batchIDs = append(batchIDs, allocation.BatchID)
+		}
+	}
+	if len(batchIDs) == 0 {
+		return nil
+	}
+
+	var batches []models.StockBatch
+	if err := tx.Unscoped().Where("id IN ?", batchIDs).Find(&batches).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.StockBatch, len(batches))
+	for i := range batches {
+		byID[batches[i].ID] = &batches[i]
+	}
+
+	movements := make([]models.StockMovement, 0, len(batchIDs))
+	for _, item := range order.Items {
+		for _, allocation := range item.Batches {
+			batch, ok := byID[allocation.BatchID]
+			if !ok {
+				return errs.ErrBatchNotFound
+			}
+			movements = append(movements, movementFor(batch, models.StockMovement{
+				Type:    movementType,
+				Delta:   sign * allocation.Quantity,
+				Reason:  reason,
+				ActorID: actorID,
+				OrderID: &order.ID,
+			}))
+		}
+	}
+	return recordStockMovements(tx, movements...)
+}
+
+func quantitiesByVariant(items []models.OrderItem) (map[uint]int, []uint, error) {
+	quantities := make(map[uint]int, len(items))
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		if item.VariantID == nil {
+			return nil, nil, errs.ErrVariantRequired
+		}
+		if _, ok := quantities[*item.VariantID]; !ok {
+			ids = append(ids, *item.VariantID)
+		}
+		quantities[*item.VariantID] += item.Quantity
+	}
+	return quantities, ids, nil
+}
+
+// redeemPromocode повторно проверяет срок действия и лимиты под блокировкой
+// и записывает использование промокода.
+func redeemPromocode(tx *gorm.DB, order *models.Order) error {
+	var promocode models.Promocode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promocode, *order.PromocodeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrPromocodeNotFound
+		}
+		return err
+	}
+
+	// промокод могли выключить или его срок мог истечь после проверки в Apply
+	if !promocode.IsValidAt(time.Now()) {
+		return errs.ErrPromocodeInactive
+	}
+
+	if promocode.UsageLimit > 0 && promocode.UsedCount >= promocode.UsageLimit {
+		return errs.ErrPromocodeUsageLimit
+	}
+
+	if promocode.PerUserLimit > 0 {
+		var used int64
+		if err := tx.Model(&models.PromocodeUsage{}).
+			Where("promocode_id = ? AND user_id = ? AND released_at IS NULL", promocode.ID, order.UserID).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		if used >= int64(promocode.PerUserLimit) {
+			return errs.ErrPromocodeUsageLimit
+		}
+	}
+
+	if err := tx.Model(&promocode).Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.PromocodeUsage{
+		PromocodeID: promocode.ID,
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+		Discount:    order.DiscountTotal,
+	}).Error
+}
+
+// releasePromocode возвращает использование промокода отменённого заказа:
+// помечает запись об использовании освобождённой (она остаётся для аудита)
+// и уменьшает общий счётчик.
+func releasePromocode(tx *gorm.DB, order *models.Order) error {
+	res := tx.Model(&models.PromocodeUsage{}).
+		Where("order_id = ? AND promocode_id = ? AND released_at IS NULL", order.ID, *order.PromocodeID).
+		Update("released_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
 
-	return nil
+	return tx.Model(&models.Promocode{}).
+		Where("id = ?", *order.PromocodeID).
+		Update("used_count", gorm.Expr("GREATEST(used_count - ?, 0)", res.RowsAffected)).Error
 }
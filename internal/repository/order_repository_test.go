@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB подключается к базе из TEST_DATABASE_DSN и создаёт нужные таблицы.
+// Без переменной тест пропускается: блокировки строк проверяются только на Postgres.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Warehouse{},
+		&models.Medicine{},
+		&models.MedicineVariant{},
+		&models.StockBatch{},
+		&models.StockMovement{},
+		&models.CartItem{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OrderItemBatch{},
+		&models.OrderEvent{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+type allocation struct {
+	batchID  uint
+	quantity int
+}
+
+func allocations(item models.OrderItem) []allocation {
+	result := make([]allocation, 0, len(item.Batches))
+	for _, b := range item.Batches {
+		result = append(result, allocation{b.BatchID, b.Quantity})
+	}
+	return result
+}
+
+func TestAllocateFEFOTakesNearestExpiryFirst(t *testing.T) {
+	december := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	variantID := uint(10)
+
+	batches := []models.StockBatch{
+		{Model: gorm.Model{ID: 1}, VariantID: variantID, Quantity: 5},
+		{Model: gorm.Model{ID: 2}, VariantID: variantID, Quantity: 3, ExpiresAt: &june},
+		{Model: gorm.Model{ID: 3}, VariantID: variantID, Quantity: 4, ExpiresAt: &december},
+	}
+	items := []models.OrderItem{{VariantID: &variantID, Quantity: 6}}
+
+	allocateFEFO(items, batches)
+
+	want := []allocation{{3, 4}, {2, 2}}
+	if got := allocations(items[0]); !slices.Equal(got, want) {
+		t.Fatalf("allocations = %v, want %v", got, want)
+	}
+	for _, b := range batches {
+		wantQuantity := map[uint]uint{1: 5, 2: 1, 3: 0}[b.ID]
+		if b.Quantity != wantQuantity {
+			t.Errorf("batch %d quantity = %d, want %d", b.ID, b.Quantity, wantQuantity)
+		}
+	}
+}
+
+func TestAllocateFEFOUsesUndatedBatchesLast(t *testing.T) {
+	january := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	variantID := uint(10)
+
+	batches := []models.StockBatch{
+		{Model: gorm.Model{ID: 1}, VariantID: variantID, Quantity: 5},
+		{Model: gorm.Model{ID: 2}, VariantID: variantID, Quantity: 2, ExpiresAt: &january},
+	}
+	items := []models.OrderItem{{VariantID: &variantID, Quantity: 4}}
+
+	allocateFEFO(items, batches)
+
+	want := []allocation{{2, 2}, {1, 2}}
+	if got := allocations(items[0]); !slices.Equal(got, want) {
+		t.Errorf("allocations = %v, want %v", got, want)
+	}
+}
+
+func TestAllocateFEFOSharesBatchesBetweenItemsOfOneVariant(t *testing.T) {
+	november := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	first, second := uint(10), uint(20)
+
+	batches := []models.StockBatch{
+		{Model: gorm.Model{ID: 1}, VariantID: first, Quantity: 3, ExpiresAt: &november},
+		{Model: gorm.Model{ID: 2}, VariantID: first, Quantity: 3, ExpiresAt: &december},
+		{Model: gorm.Model{ID: 3}, VariantID: second, Quantity: 2, ExpiresAt: &november},
+	}
+	items := []models.OrderItem{
+		{VariantID: &first, Quantity: 2},
+		{VariantID: &second, Quantity: 2},
+		{VariantID: &first, Quantity: 3},
+	}
+
+	allocateFEFO(items, batches)
+
+	wants := [][]allocation{
+		{{1, 2}},
+		{{3, 2}},
+		{{1, 1}, {2, 2}},
+	}
+	for i, want := range wants {
+		if got := allocations(items[i]); !slices.Equal(got, want) {
+			t.Errorf("item %d allocations = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestAllocateFEFOBreaksExpiryTiesByID(t *testing.T) {
+	december := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	variantID := uint(10)
+
+	batches := []models.StockBatch{
+		{Model: gorm.Model{ID: 7}, VariantID: variantID, Quantity: 1, ExpiresAt: &december},
+		{Model: gorm.Model{ID: 4}, VariantID: variantID, Quantity: 1, ExpiresAt: &december},
+	}
+	items := []models.OrderItem{{VariantID: &variantID, Quantity: 1}}
+
+	allocateFEFO(items, batches)
+
+	want := []allocation{{4, 1}}
+	if got := allocations(items[0]); !slices.Equal(got, want) {
+		t.Errorf("allocations = %v, want %v", got, want)
+	}
+}
+
+func TestCreateOrderWithClearCartSellsLastPackOnce(t *testing.T) {
+	db := openTestDB(t)
+	suffix := fmt.Sprint(time.Now().UnixNano())
+
+	warehouse := models.Warehouse{Code: "RACE-" + suffix, Name: "race", City: "race", Address: "race"}
+	if err := db.Create(&warehouse).Error; err != nil {
+		t.Fatal(err)
+	}
+	medicine := models.Medicine{Name: "race " + suffix, Manufacturer: "race", StockQuantity: 1}
+	if err := db.Omit("Category", "Subcategory").Create(&medicine).Error; err != nil {
+		t.Fatal(err)
+	}
+	variant := models.MedicineVariant{MedicineID: medicine.ID, SKU: "RACE-" + suffix, Name: "1 pack", Price: 100, StockQuantity: 1}
+	if err := db.Create(&variant).Error; err != nil {
+		t.Fatal(err)
+	}
+	batch := models.StockBatch{
+		VariantID: variant.ID, MedicineID: medicine.ID, WarehouseID: warehouse.ID,
+		LotNumber: "RACE", Quantity: 1, ReceivedQuantity: 1,
+	}
+	if err := db.Create(&batch).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewOrderRepository(db)
+	var users []models.User
+	for i := range 2 {
+		user := models.User{FullName: "race", Email: fmt.Sprintf("race-%d-%s@example.com", i, suffix)}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+
+	start := make(chan struct{})
+	results := make([]error, len(users))
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cart := models.Cart{UserID: user.ID}
+			if err := db.Create(&cart).Error; err != nil {
+				results[i] = err
+				return
+			}
+			order := &models.Order{
+				UserID:         user.ID,
+				Status:         models.OrderStatusPendingPayment,
+				TotalPrice:     100,
+				FinalPrice:     100,
+				FulfilmentMode: models.FulfilmentPickup,
+				WarehouseID:    &warehouse.ID,
+				Items: []models.OrderItem{{
+					MedicineID: medicine.ID, VariantID: &variant.ID, MedicineName: medicine.Name,
+					Quantity: 1, PricePerUnit: 100, LineTotal: 100,
+				}},
+			}
+			<-start
+			results[i] = repo.CreateOrderWithClearCart(order, cart.ID)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	var succeeded, rejected int
+	for _, err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, errs.ErrInsufficientStock):
+			rejected++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 || rejected != 1 {
+		t.Fatalf("succeeded = %d, rejected = %d, want 1 and 1", succeeded, rejected)
+	}
+
+	if err := db.First(&batch, batch.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if batch.Quantity != 0 {
+		t.Errorf("batch quantity = %d, want 0", batch.Quantity)
+	}
+	if err := db.First(&variant, variant.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if variant.StockQuantity != 0 || variant.InStock {
+		t.Errorf("variant stock = %d (in stock %v), want 0 and not in stock", variant.StockQuantity, variant.InStock)
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository interface {
+	Create(payment *models.Payment) error
+	GetByID(paymentID uint) (*models.Payment, error)
+	ListByOrder(orderID uint) ([]models.Payment, error)
+	// Fail переводит ожидающий платёж в failed. Если платёж уже обработан,
+	// возвращает ErrPaymentAlreadyProcessed.
+	Fail(payment *models.Payment) error
+	ConfirmWithOrder(payment *models.Payment, actorID *uint) (*models.Refund, error)
+}
+
+type gormPaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &gormPaymentRepository{db: db}
+}
+
+func (r *gormPaymentRepository) Create(payment *models.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *gormPaymentRepository) GetByID(paymentID uint) (*models.Payment, error) {
+	var payment models.Payment
+
+	if err := r.db.First(&payment, paymentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *gormPaymentRepository) ListByOrder(orderID uint) ([]models.Payment, error) {
+	var list []models.Payment
+
+	if err := r.db.Where("order_id = ?", orderID).Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormPaymentRepository) Fail(payment *models.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// условие на статус не даёт затереть платёж, который успели подтвердить
+		res := tx.Model(&models.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, models.StatusPending).
+			Updates(map[string]any{
+				"status":         models.StatusFailed,
+				"failure_reason": payment.FailureReason,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errs.ErrPaymentAlreadyProcessed
+		}
+		return tx.First(payment, payment.ID).Error
+	})
+}
+
+// ConfirmWithOrder сохраняет успешный платёж и переводит заказ в paid одной транзакцией.
+// Статус платежа перепроверяется под блокировкой заказа: его могли отклонить, пока шло подтверждение.
+// Если заказ за это время отменили или уже оплатили, деньги всё равно списаны: платёж
+// сохраняется успешным, а на его сумму создаётся и возвращается ожидающий возврат.
+func (r *gormPaymentRepository) ConfirmWithOrder(payment *models.Payment, actorID *uint) (*models.Refund, error) {
+	var refund *models.Refund
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, payment.OrderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrOrderNotFound
+			}
+			return err
+		}
+
+		var current models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, payment.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrPaymentNotFound
+			}
+			return err
+		}
+		if current.Status != models.StatusPending {
+			return errs.ErrPaymentAlreadyProcessed
+		}
+
+		if err := tx.Save(payment).Error; err != nil {
+			return err
+		}
+
+		if !models.CanChangeOrderStatus(order.Status, models.OrderStatusPaid) {
+			refund = &models.Refund{
+				PaymentID: payment.ID,
+				OrderID:   order.ID,
+				Amount:    payment.Amount,
+				Status:    models.StatusPending,
+				Reason:    fmt.Sprintf("order is %s, payment cannot be applied", order.Status),
+			}
+			return tx.Create(refund).Error
+		}
+
+		from := order.Status
+		if err := tx.Model(&order).Update("status", models.OrderStatusPaid).Error; err != nil {
+			return err
+		}
+
+		return recordOrderEvent(tx, order.ID, from, models.OrderStatusPaid, actorID,
+			fmt.Sprintf("payment %d confirmed", payment.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PickupPointRepository interface {
+	Create(point *models.PickupPoint) error
+	GetByID(id uint) (*models.PickupPoint, error)
+	// List возвращает пункты города (если city не пуст) в порядке названий.
+	List(city string, onlyActive bool) ([]models.PickupPoint, error)
+	Update(point *models.PickupPoint) error
+}
+
+type gormPickupPointRepository struct {
+	db *gorm.DB
+}
+
+func NewPickupPointRepository(db *gorm.DB) PickupPointRepository {
+	return &gormPickupPointRepository{db: db}
+}
+
+func (r *gormPickupPointRepository) Create(point *models.PickupPoint) error {
+	return r.db.Create(point).Error
+}
+
+func (r *gormPickupPointRepository) GetByID(id uint) (*models.PickupPoint, error) {
+	var point models.PickupPoint
+	if err := r.db.First(&point, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrPickupPointNotFound
+		}
+		return nil, err
+	}
+	return &point, nil
+}
+
+func (r *gormPickupPointRepository) List(city string, onlyActive bool) ([]models.PickupPoint, error) {
+	db := r.db
+	if city != "" {
+		db = db.Where("LOWER(city) = LOWER(?)", city)
+	}
+	if onlyActive {
+		db = db.Where("is_active = ?", true)
+	}
+
+	var points []models.PickupPoint
+	if err := db.Order("city, name, id").Find(&points).Error; err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+func (r *gormPickupPointRepository) Update(point *models.PickupPoint) error {
+	return r.db.Save(point).Error
+}
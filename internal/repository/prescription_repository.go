@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PrescriptionRepository interface {
+	Create(prescription *models.Prescription) error
+	GetByID(id uint) (*models.Prescription, error)
+	ListByUser(userID uint) ([]models.Prescription, error)
+	ListByStatus(status models.PrescriptionStatus) ([]models.Prescription, error)
+	Update(prescription *models.Prescription) error
+	CoveredMedicineIDs(userID uint, medicineIDs []uint, at time.Time) ([]uint, error)
+}
+
+type gormPrescriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewPrescriptionRepository(db *gorm.DB) PrescriptionRepository {
+	return &gormPrescriptionRepository{db: db}
+}
+
+func (r *gormPrescriptionRepository) Create(prescription *models.Prescription) error {
+	return r.db.Omit("Medicines.*").Create(prescription).Error
+}
+
+func (r *gormPrescriptionRepository) GetByID(id uint) (*models.Prescription, error) {
+	var prescription models.Prescription
+
+	if err := r.db.Preload("Medicines").First(&prescription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrPrescriptionNotFound
+		}
+		return nil, err
+	}
+	return &prescription, nil
+}
+
+func (r *gormPrescriptionRepository) ListByUser(userID uint) ([]models.Prescription, error) {
+	var list []models.Prescription
+
+	if err := r.db.Preload("Medicines").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormPrescriptionRepository) ListByStatus(status models.PrescriptionStatus) ([]models.Prescription, error) {
+	var list []models.Prescription
+
+	if err := r.db.Preload("Medicines").
+		Where("status = ?", status).
+		Order("created_at").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormPrescriptionRepository) Update(prescription *models.Prescription) error {
+	return r.db.Omit("Medicines").Save(prescription).Error
+}
+
+// CoveredMedicineIDs возвращает те из medicineIDs, на которые у пользователя есть
+// одобренный и не истёкший рецепт.
+func (r *gormPrescriptionRepository) CoveredMedicineIDs(userID uint, medicineIDs []uint, at time.Time) ([]uint, error) {
+	var covered []uint
+
+	err := r.db.Table("prescription_medicines AS pm").
+		Distinct("pm.medicine_id").
+		Joins("JOIN prescriptions p ON p.id = pm.prescription_id").
+		Where("p.user_id = ? AND p.status = ? AND p.deleted_at IS NULL", userID, models.PrescriptionStatusApproved).
+		Where("p.valid_until IS NULL OR p.valid_until > ?", at).
+		Where("pm.medicine_id IN ?", medicineIDs).
+		Pluck("pm.medicine_id", &covered).Error
+	return covered, err
+}
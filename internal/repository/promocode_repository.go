@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PromocodeRepository interface {
+	Create(promocode *models.Promocode) error
+	GetByID(id uint) (*models.Promocode, error)
+	GetByCode(code string) (*models.Promocode, error)
+	List() ([]models.Promocode, error)
+	Update(promocode *models.Promocode) error
+	Delete(id uint) error
+	// CountUsagesByUser не учитывает применения, освобождённые отменой заказа.
+	CountUsagesByUser(promocodeID, userID uint) (int64, error)
+	// ListUsages возвращает все применения, включая освобождённые.
+	ListUsages(promocodeID uint) ([]models.PromocodeUsage, error)
+}
+
+type gormPromocodeRepository struct {
+	db *gorm.DB
+}
+
+func NewPromocodeRepository(db *gorm.DB) PromocodeRepository {
+	return &gormPromocodeRepository{db: db}
+}
+
+func (r *gormPromocodeRepository) Create(promocode *models.Promocode) error {
+	return r.db.Create(promocode).Error
+}
+
+func (r *gormPromocodeRepository) GetByID(id uint) (*models.Promocode, error) {
+	var promocode models.Promocode
+
+	if err := r.db.First(&promocode, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrPromocodeNotFound
+		}
+		return nil, err
+	}
+	return &promocode, nil
+}
+
+func (r *gormPromocodeRepository) GetByCode(code string) (*models.Promocode, error) {
+	var promocode models.Promocode
+
+	if err := r.db.Where("code = ?", code).First(&promocode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrPromocodeNotFound
+		}
+		return nil, err
+	}
+	return &promocode, nil
+}
+
+func (r *gormPromocodeRepository) List() ([]models.Promocode, error) {
+	var list []models.Promocode
+
+	if err := r.db.Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormPromocodeRepository) Update(promocode *models.Promocode) error {
+	return r.db.Save(promocode).Error
+}
+
+func (r *gormPromocodeRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.Promocode{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrPromocodeNotFound
+	}
+	return nil
+}
+
+func (r *gormPromocodeRepository) CountUsagesByUser(promocodeID, userID uint) (int64, error) {
+	var count int64
+
+	err := r.db.Model(&models.PromocodeUsage{}).
+		Where("promocode_id = ? AND user_id = ? AND released_at IS NULL", promocodeID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormPromocodeRepository) ListUsages(promocodeID uint) ([]models.PromocodeUsage, error) {
+	var list []models.PromocodeUsage
+
+	if err := r.db.Where("promocode_id = ?", promocodeID).Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
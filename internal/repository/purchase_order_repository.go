@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PurchaseOrderFilter - выборка заказов поставщикам. Нулевые значения не фильтруют.
+type PurchaseOrderFilter struct {
+	Status      models.PurchaseOrderStatus
+	SupplierID  *uint
+	WarehouseID *uint
+}
+
+// PurchaseReceipt - принятое по строке заказа количество одной серии.
+type PurchaseReceipt struct {
+	LineID    uint
+	LotNumber string
+	ExpiresAt time.Time
+	Quantity  uint
+}
+
+type PurchaseOrderRepository interface {
+	Create(order *models.PurchaseOrder) error
+	GetByID(id uint) (*models.PurchaseOrder, error)
+	List(filter PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+	// Update сохраняет черновик; если lines не nil, строки заменяются целиком.
+	Update(order *models.PurchaseOrder, lines []models.PurchaseOrderLine) error
+	// Send переводит черновик в статус sent.
+	Send(id uint) (*models.PurchaseOrder, error)
+	// Receive в одной транзакции заводит партии по принятым строкам, записывает
+	// приход в журнал движений, пересчитывает остатки и статус заказа.
+	Receive(id uint, receipts []PurchaseReceipt, actorID *uint) (*models.PurchaseOrder, error)
+}
+
+type gormPurchaseOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(db *gorm.DB) PurchaseOrderRepository {
+	return &gormPurchaseOrderRepository{db: db}
+}
+
+func (r *gormPurchaseOrderRepository) Create(order *models.PurchaseOrder) error {
+	return r.db.Create(order).Error
+}
+
+func (r *gormPurchaseOrderRepository) GetByID(id uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := r.db.Preload("Supplier").Preload("Warehouse").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&order, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrPurchaseOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *gormPurchaseOrderRepository) List(filter PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	db := r.db.Preload("Supplier").Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.SupplierID != nil {
+		db = db.Where("supplier_id = ?", *filter.SupplierID)
+	}
+	if filter.WarehouseID != nil {
+		db = db.Where("warehouse_id = ?", *filter.WarehouseID)
+	}
+
+	var orders []models.PurchaseOrder
+	if err := db.Order("created_at DESC, id DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *gormPurchaseOrderRepository) Update(order *models.PurchaseOrder, lines []models.PurchaseOrderLine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockDraftPurchaseOrder(tx, order.ID); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(order).Error; err != nil {
+			return err
+		}
+		if lines == nil {
+			return nil
+		}
+
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].PurchaseOrderID = order.ID
+		}
+		if len(lines) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&lines).Error; err != nil {
+				return err
+			}
+		}
+		order.Lines = lines
+		return nil
+	})
+}
+
+func (r *gormPurchaseOrderRepository) Send(id uint) (*models.PurchaseOrder, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockDraftPurchaseOrder(tx, id); err != nil {
+			return err
+		}
+		return tx.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(map[string]any{
+			"status":  models.PurchaseOrderSent,
+			"sent_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *gormPurchaseOrderRepository) Receive(id uint, receipts []PurchaseReceipt, actorID *uint) (*models.PurchaseOrder, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrPurchaseOrderNotFound
+			}
+			return err
+		}
+		if !order.Status.CanReceive() {
+			return errs.ErrPurchaseOrderNotSent
+		}
+
+		var lines []models.PurchaseOrderLine
+		if err := tx.Where("purchase_order_id = ?", order.ID).Order("id").Find(&lines).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.PurchaseOrderLine, len(lines))
+		for i := range lines {
+			byID[lines[i].ID] = &lines[i]
+		}
+		for _, receipt := range receipts {
+			if _, ok := byID[receipt.LineID]; !ok {
+				return errs.ErrInvalidReceipt
+			}
+		}
+
+		variantIDs := make([]uint, 0, len(receipts))
+		for _, receipt := range receipts {
+			variantIDs = append(variantIDs, byID[receipt.LineID].VariantID)
+		}
+		if _, err := lockVariants(tx, variantIDs...); err != nil {
+			return err
+		}
+
+		for _, receipt := range receipts {
+			line := byID[receipt.LineID]
+			if line.ReceivedQuantity+receipt.Quantity > line.Quantity {
+				return errs.ErrInvalidReceipt
+			}
+			line.ReceivedQuantity += receipt.Quantity
+
+			expiresAt := receipt.ExpiresAt
+			batch := &models.StockBatch{
+				VariantID:   line.VariantID,
+				WarehouseID: order.WarehouseID,
+				LotNumber:   receipt.LotNumber,
+				ExpiresAt:   &expiresAt,
+				Quantity:    receipt.Quantity,
+			}
+			err := receiveBatch(tx, batch, models.StockMovement{
+				Reason:       "purchase order received",
+				ActorID:      actorID,
+				DocumentType: models.StockDocumentPurchaseOrder,
+				DocumentID:   &order.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		received := true
+		for i := range lines {
+			if err := tx.Model(&lines[i]).Update("received_quantity", lines[i].ReceivedQuantity).Error; err != nil {
+				return err
+			}
+			if lines[i].ReceivedQuantity < lines[i].Quantity {
+				received = false
+			}
+		}
+
+		updates := map[string]any{"status": models.PurchaseOrderPartiallyReceived}
+		if received {
+			updates = map[string]any{"status": models.PurchaseOrderReceived, "received_at": time.Now()}
+		}
+		return tx.Model(&order).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func lockDraftPurchaseOrder(tx *gorm.DB, id uint) error {
+	var order models.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrPurchaseOrderNotFound
+		}
+		return err
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return errs.ErrPurchaseOrderNotDraft
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecallRepository interface {
+	// Create снимает с продажи партии отозванных серий, помечает открытые корзины
+	// и неотгруженные заказы и собирает список оповещения - всё в одной транзакции.
+	Create(recall *models.Recall) error
+	GetByID(id uint) (*models.Recall, error)
+	List() ([]models.Recall, error)
+}
+
+type gormRecallRepository struct {
+	db *gorm.DB
+}
+
+func NewRecallRepository(db *gorm.DB) RecallRepository {
+	return &gormRecallRepository{db: db}
+}
+
+func (r *gormRecallRepository) Create(recall *models.Recall) error {
+	lots := make([]string, 0, len(recall.Lots))
+	for _, lot := range recall.Lots {
+		lots = append(lots, lot.LotNumber)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var variantIDs []uint
+		if err := tx.Model(&models.StockBatch{}).
+			Where("medicine_id = ? AND lot_number IN ? AND recall_id IS NULL", recall.MedicineID, lots).
+			Distinct().Order("variant_id").
+			Pluck("variant_id", &variantIDs).Error; err != nil {
+			return err
+		}
+		if len(variantIDs) == 0 {
+			return errs.ErrRecallLotsNotFound
+		}
+
+		if _, err := lockVariants(tx, variantIDs...); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(recall).Error; err != nil {
+			return err
+		}
+		for i := range recall.Lots {
+			recall.Lots[i].RecallID = recall.ID
+		}
+		if err := tx.Create(&recall.Lots).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.StockBatch{}).
+			Where("medicine_id = ? AND lot_number IN ? AND recall_id IS NULL", recall.MedicineID, lots).
+			Update("recall_id", recall.ID).Error; err != nil {
+			return err
+		}
+		if err := syncVariantStock(tx, time.Now(), variantIDs...); err != nil {
+			return err
+		}
+
+		recalledBatches := tx.Model(&models.StockBatch{}).Select("id").Where("recall_id = ?", recall.ID)
+		itemsWithRecalledBatches := tx.Model(&models.OrderItemBatch{}).
+			Select("order_items.order_id").
+			Joins("JOIN order_items ON order_items.id = order_item_batches.order_item_id").
+			Where("order_item_batches.batch_id IN (?)", recalledBatches)
+
+		if err := tx.Model(&models.Order{}).
+			Where("id IN (?) AND status IN ?", itemsWithRecalledBatches, models.RecallFlaggedOrderStatuses).
+			Update("recall_id", recall.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CartItem{}).
+			Where("variant_id IN ?", variantIDs).
+			Update("recall_id", recall.ID).Error; err != nil {
+			return err
+		}
+
+		// в список оповещения попадают все, кому товар мог быть продан, кроме отменённых заказов
+		var orders []models.Order
+		if err := tx.Where("id IN (?) AND status <> ?", itemsWithRecalledBatches, models.OrderStatusCanceled).
+			Order("user_id, id").
+			Find(&orders).Error; err != nil {
+			return err
+		}
+		var carts []models.Cart
+		if err := tx.Where("id IN (?)", tx.Model(&models.CartItem{}).Select("cart_id").Where("recall_id = ?", recall.ID)).
+			Order("user_id").
+			Find(&carts).Error; err != nil {
+			return err
+		}
+
+		notifications := make([]models.RecallNotification, 0, len(orders)+len(carts))
+		for _, order := range orders {
+			notifications = append(notifications, models.RecallNotification{
+				RecallID:    recall.ID,
+				UserID:      order.UserID,
+				Source:      models.RecallNoticeOrder,
+				OrderID:     &order.ID,
+				OrderStatus: order.Status,
+			})
+		}
+		for _, cart := range carts {
+			notifications = append(notifications, models.RecallNotification{
+				RecallID: recall.ID,
+				UserID:   cart.UserID,
+				Source:   models.RecallNoticeCart,
+				CartID:   &cart.ID,
+			})
+		}
+		if len(notifications) > 0 {
+			if err := tx.Create(&notifications).Error; err != nil {
+				return err
+			}
+		}
+		recall.Notifications = notifications
+		return nil
+	})
+}
+
+func (r *gormRecallRepository) GetByID(id uint) (*models.Recall, error) {
+	var recall models.Recall
+	err := r.db.Preload("Medicine").Preload("Lots").
+		Preload("Notifications", func(db *gorm.DB) *gorm.DB { return db.Order("user_id, id") }).
+		Preload("Notifications.User").
+		First(&recall, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecallNotFound
+		}
+		return nil, err
+	}
+	return &recall, nil
+}
+
+func (r *gormRecallRepository) List() ([]models.Recall, error) {
+	var recalls []models.Recall
+	if err := r.db.Preload("Medicine").Preload("Lots").
+		Order("created_at DESC, id DESC").
+		Find(&recalls).Error; err != nil {
+		return nil, err
+	}
+	return recalls, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	GetByHash(tokenHash string) (*models.RefreshToken, error)
+	Rotate(oldTokenID uint, newToken *models.RefreshToken) error
+	Revoke(tokenID uint) error
+	RevokeAllForUser(userID uint) error
+}
+
+type gormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &gormRefreshTokenRepository{db: db}
+}
+
+func (r *gormRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *gormRefreshTokenRepository) GetByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate отзывает старый токен и сохраняет новый в одной транзакции. Если старый
+// токен уже был отозван параллельным запросом, возвращается ErrInvalidToken.
+func (r *gormRefreshTokenRepository) Rotate(oldTokenID uint, newToken *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var old models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&old, oldTokenID).Error; err != nil {
+			return err
+		}
+		if old.RevokedAt != nil {
+			return errs.ErrInvalidToken
+		}
+
+		if err := tx.Create(newToken).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&old).Updates(map[string]any{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": newToken.ID,
+		}).Error
+	})
+}
+
+func (r *gormRefreshTokenRepository) Revoke(tokenID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", tokenID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *gormRefreshTokenRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShipmentRepository interface {
+	// Create заводит отправление для оплаченного заказа с доставкой.
+	Create(shipment *models.Shipment) error
+	GetByID(id uint) (*models.Shipment, error)
+	GetByOrderID(orderID uint) (*models.Shipment, error)
+	// ListByCourier возвращает отправления курьера, при onlyActive - ещё не вручённые.
+	ListByCourier(courierID uint, onlyActive bool) ([]models.Shipment, error)
+	// Update меняет курьера, службу доставки и трек-номер невручённого отправления.
+	Update(shipment *models.Shipment) error
+	// Dispatch передаёт отправление в доставку и переводит заказ в shipped.
+	Dispatch(id uint, actorID *uint) (*models.Shipment, error)
+	// RecordAttempt записывает попытку вручения; успешная завершает заказ.
+	// Курьером попытки записывается назначенный на отправку курьер, actorID попадает
+	// в историю заказа. Если asCourier, попытку принимает только от назначенного курьера.
+	RecordAttempt(id uint, attempt *models.DeliveryAttempt, actorID *uint, asCourier bool) (*models.Shipment, error)
+}
+
+type gormShipmentRepository struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &gormShipmentRepository{db: db}
+}
+
+func (r *gormShipmentRepository) Create(shipment *models.Shipment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, shipment.OrderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrOrderNotFound
+			}
+			return err
+		}
+		if order.Status != models.OrderStatusPaid || order.Fulfilment() != models.FulfilmentDelivery {
+			return errs.ErrShipmentNotAllowed
+		}
+		if order.RecallID != nil {
+			return errs.ErrOrderRecalled
+		}
+
+		var existing int64
+		if err := tx.Model(&models.Shipment{}).Where("order_id = ?", order.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errs.ErrShipmentExists
+		}
+
+		shipment.Status = models.ShipmentCreated
+		return tx.Omit(clause.Associations).Create(shipment).Error
+	})
+}
+
+func (r *gormShipmentRepository) GetByID(id uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+	if err := r.preload(r.db).First(&shipment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrShipmentNotFound
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+func (r *gormShipmentRepository) GetByOrderID(orderID uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+	if err := r.preload(r.db).Where("order_id = ?", orderID).First(&shipment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrShipmentNotFound
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+func (r *gormShipmentRepository) ListByCourier(courierID uint, onlyActive bool) ([]models.Shipment, error) {
+	db := r.preload(r.db).Preload("Order").Where("courier_id = ?", courierID)
+	if onlyActive {
+		db = db.Where("status <> ?", models.ShipmentDelivered)
+	}
+
+	var shipments []models.Shipment
+	if err := db.Order("created_at, id").Find(&shipments).Error; err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+func (r *gormShipmentRepository) Update(shipment *models.Shipment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockShipment(tx, shipment.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status == models.ShipmentDelivered {
+			return errs.ErrInvalidShipmentState
+		}
+		return tx.Model(current).Updates(map[string]any{
+			"courier_id":      shipment.CourierID,
+			"carrier":         shipment.Carrier,
+			"tracking_number": shipment.TrackingNumber,
+		}).Error
+	})
+}
+
+func (r *gormShipmentRepository) Dispatch(id uint, actorID *uint) (*models.Shipment, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		shipment, err := lockShipment(tx, id)
+		if err != nil {
+			return err
+		}
+		if shipment.Status != models.ShipmentCreated {
+			return errs.ErrInvalidShipmentState
+		}
+		if shipment.CourierID == nil && shipment.TrackingNumber == nil {
+			return errs.ErrCourierRequired
+		}
+
+		if err := changeShipmentOrderStatus(tx, shipment.OrderID, models.OrderStatusShipped, actorID, "shipment dispatched"); err != nil {
+			return err
+		}
+		return tx.Model(shipment).Updates(map[string]any{
+			"status":     models.ShipmentInTransit,
+			"shipped_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *gormShipmentRepository) RecordAttempt(id uint, attempt *models.DeliveryAttempt, actorID *uint,
+	asCourier bool) (*models.Shipment, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		shipment, err := lockShipment(tx, id)
+		if err != nil {
+			return err
+		}
+		// назначение проверяем под блокировкой: курьера могли сменить
+		if asCourier && (shipment.CourierID == nil || actorID == nil || *shipment.CourierID != *actorID) {
+			return errs.ErrForbidden
+		}
+		if shipment.Status != models.ShipmentInTransit {
+			return errs.ErrInvalidShipmentState
+		}
+
+		attempt.ShipmentID = shipment.ID
+		attempt.CourierID = shipment.CourierID
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		if attempt.Outcome != models.DeliveryAttemptDelivered {
+			return nil
+		}
+
+		if err := changeShipmentOrderStatus(tx, shipment.OrderID, models.OrderStatusCompleted, actorID, "delivered"); err != nil {
+			return err
+		}
+		return tx.Model(shipment).Updates(map[string]any{
+			"status":       models.ShipmentDelivered,
+			"delivered_at": attempt.CreatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *gormShipmentRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Attempts", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") })
+}
+
+func lockShipment(tx *gorm.DB, id uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shipment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrShipmentNotFound
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// changeShipmentOrderStatus переводит заказ отправления в новый статус и пишет
+// переход в журнал событий заказа. Заказ с товаром из отозванной серии не отгружается.
+func changeShipmentOrderStatus(tx *gorm.DB, orderID uint, status models.OrderStatus, actorID *uint, reason string) error {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrOrderNotFound
+		}
+		return err
+	}
+	if !order.CanChangeStatus(status) {
+		return errs.ErrInvalidStatusTransition
+	}
+	if order.RecallID != nil && status == models.OrderStatusShipped {
+		return errs.ErrOrderRecalled
+	}
+
+	from := order.Status
+	if err := tx.Model(&order).Update("status", status).Error; err != nil {
+		return err
+	}
+	return recordOrderEvent(tx, order.ID, from, status, actorID, reason)
+}
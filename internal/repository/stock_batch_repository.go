@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sellableBatchCondition - партия не отозвана и ещё не просрочена на переданный момент.
+const sellableBatchCondition = "(recall_id IS NULL AND (expires_at IS NULL OR expires_at > ?))"
+
+type StockBatchRepository interface {
+	// Create заводит поступившую партию и записывает приход в журнал движений.
+	Create(batch *models.StockBatch, actorID *uint) error
+	// ApplyMovement списывает или корректирует остаток партии. Тип, Delta, причина
+	// и автор берутся из movement, остальные поля заполняются по партии.
+	ApplyMovement(batchID uint, movement *models.StockMovement) error
+	GetByID(id uint) (*models.StockBatch, error)
+	ListByMedicine(medicineID uint) ([]models.StockBatch, error)
+	// RefreshExpiredStock пересчитывает остатки вариантов, у которых с прошлого
+	// пересчёта истёк срок годности партий. Возвращает число обновлённых вариантов.
+	RefreshExpiredStock(now time.Time) (int, error)
+}
+
+type gormStockBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewStockBatchRepository(db *gorm.DB) StockBatchRepository {
+	return &gormStockBatchRepository{db: db}
+}
+
+func (r *gormStockBatchRepository) Create(batch *models.StockBatch, actorID *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return receiveBatch(tx, batch, models.StockMovement{ActorID: actorID, Reason: "batch received"})
+	})
+}
+
+// receiveBatch под блокировкой варианта создаёт партию, записывает приход в журнал
+// (тип, автор, причина и документ берутся из movement) и пересчитывает остатки.
+func receiveBatch(tx *gorm.DB, batch *models.StockBatch, movement models.StockMovement) error {
+	var variant models.MedicineVariant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, batch.VariantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrVariantNotFound
+		}
+		return err
+	}
+
+	batch.MedicineID = variant.MedicineID
+	if batch.ReceivedQuantity == 0 {
+		batch.ReceivedQuantity = batch.Quantity
+	}
+	if err := tx.Omit(clause.Associations).Create(batch).Error; err != nil {
+		return err
+	}
+
+	movement.Type = models.StockMovementReceipt
+	movement.Delta = int(batch.Quantity)
+	if err := recordStockMovements(tx, movementFor(batch, movement)); err != nil {
+		return err
+	}
+	return syncVariantStock(tx, time.Now(), variant.ID)
+}
+
+func (r *gormStockBatchRepository) ApplyMovement(batchID uint, movement *models.StockMovement) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var batch models.StockBatch
+		if err := tx.First(&batch, batchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrBatchNotFound
+			}
+			return err
+		}
+
+		if _, err := lockVariants(tx, batch.VariantID); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, batchID).Error; err != nil {
+			return err
+		}
+
+		quantity := int(batch.Quantity) + movement.Delta
+		if quantity < 0 {
+			return fmt.Errorf("%w: batch %s", errs.ErrInsufficientStock, batch.LotNumber)
+		}
+		if err := tx.Model(&batch).Update("quantity", quantity).Error; err != nil {
+			return err
+		}
+
+		*movement = movementFor(&batch, *movement)
+		if err := recordStockMovements(tx, *movement); err != nil {
+			return err
+		}
+		return syncVariantStock(tx, time.Now(), batch.VariantID)
+	})
+}
+
+func (r *gormStockBatchRepository) GetByID(id uint) (*models.StockBatch, error) {
+	var batch models.StockBatch
+	if err := r.db.First(&batch, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrBatchNotFound
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *gormStockBatchRepository) ListByMedicine(medicineID uint) ([]models.StockBatch, error) {
+	var batches []models.StockBatch
+	if err := r.db.Where("medicine_id = ?", medicineID).
+		Order("variant_id, warehouse_id, expires_at NULLS LAST, id").
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *gormStockBatchRepository) RefreshExpiredStock(now time.Time) (int, error) {
+	var ids []uint
+	err := r.db.Raw(`SELECT v.id FROM medicine_variants v
+		LEFT JOIN (SELECT variant_id, SUM(quantity) AS quantity FROM stock_batches
+			WHERE deleted_at IS NULL AND `+sellableBatchCondition+`
+			GROUP BY variant_id) b ON b.variant_id = v.id
+		WHERE v.deleted_at IS NULL AND v.stock_quantity <> COALESCE(b.quantity, 0)
+		ORDER BY v.id`, now).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVariants(tx, ids...); err != nil {
+			return err
+		}
+		return syncVariantStock(tx, now, ids...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// lockVariants блокирует строки вариантов, включая удалённые (их партии ещё
+// возвращаются и списываются). Все операции с остатками берут блокировки в одном
+// порядке: сначала варианты по возрастанию id, затем их партии. Поэтому
+// резервирование, отмена, приёмка, отзыв и инвентаризация не могут взаимно
+// заблокировать друг друга.
+func lockVariants(tx *gorm.DB, ids ...uint) ([]models.MedicineVariant, error) {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var variants []models.MedicineVariant
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// syncVariantStock пересчитывает остаток вариантов как сумму непросроченных партий,
+// а затем агрегаты их лекарств. Вызывающий должен держать блокировку строк вариантов.
+func syncVariantStock(tx *gorm.DB, now time.Time, variantIDs ...uint) error {
+	ids := slices.Clone(variantIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, id := range ids {
+		err := tx.Exec(`UPDATE medicine_variants SET
+				stock_quantity = s.stock,
+				in_stock = s.stock > 0,
+				updated_at = NOW()
+			FROM (SELECT COALESCE(SUM(quantity), 0) AS stock FROM stock_batches
+				WHERE variant_id = ? AND deleted_at IS NULL AND `+sellableBatchCondition+`) s
+			WHERE medicine_variants.id = ?`, id, now, id).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var medicineIDs []uint
+	if err := tx.Unscoped().Model(&models.MedicineVariant{}).
+		Where("id IN ?", ids).
+		Pluck("medicine_id", &medicineIDs).Error; err != nil {
+		return err
+	}
+	return syncMedicineStock(tx, medicineIDs...)
+}
+
+// MigrateStockBatches переносит остаток вариантов, заведённых до учёта партий,
+// в партию LEGACY без срока годности и привязывает к ней позиции старых заказов,
+// чтобы отмена таких заказов возвращала товар в партию. Вызывается после
+// MigrateMedicineVariants при каждом запуске, поэтому партия создаётся только
+// вариантам с остатком или со старыми заказами: новый вариант без поступлений
+// не должен получать пустую партию.
+func MigrateStockBatches(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO stock_batches
+				(created_at, updated_at, variant_id, medicine_id, lot_number, quantity, received_quantity)
+			SELECT NOW(), NOW(), v.id, v.medicine_id, ?, v.stock_quantity, v.stock_quantity
+			FROM medicine_variants v
+			WHERE NOT EXISTS (SELECT 1 FROM stock_batches b WHERE b.variant_id = v.id)
+				AND (v.stock_quantity > 0 OR EXISTS (
+					SELECT 1 FROM order_items oi
+					WHERE oi.variant_id = v.id
+						AND NOT EXISTS (SELECT 1 FROM order_item_batches ob WHERE ob.order_item_id = oi.id)))`,
+			models.LegacyLotNumber).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`INSERT INTO order_item_batches (order_item_id, batch_id, lot_number, quantity)
+			SELECT oi.id, b.id, b.lot_number, oi.quantity
+			FROM order_items oi
+			JOIN stock_batches b ON b.variant_id = oi.variant_id AND b.lot_number = ?
+			WHERE NOT EXISTS (SELECT 1 FROM order_item_batches ob WHERE ob.order_item_id = oi.id)`,
+			models.LegacyLotNumber).Error
+	})
+}
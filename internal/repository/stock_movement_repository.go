@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"time"
+
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StockMovementFilter - выборка журнала движений лекарства. Нулевые значения не фильтруют.
+type StockMovementFilter struct {
+	MedicineID  uint
+	VariantID   *uint
+	WarehouseID *uint
+	Type        models.StockMovementType
+	From        *time.Time
+	To          *time.Time
+
+	Offset int
+	Limit  int
+}
+
+// StockMovementEntry - движение и остаток в рамках фильтра (без учёта периода) после него.
+type StockMovementEntry struct {
+	models.StockMovement
+	Balance int
+}
+
+type StockMovementRepository interface {
+	// List возвращает движения от новых к старым и общее их число.
+	List(filter StockMovementFilter) ([]StockMovementEntry, int64, error)
+	// Balance - текущий остаток по журналу в рамках фильтра (период и тип не учитываются).
+	Balance(filter StockMovementFilter) (int64, error)
+}
+
+type gormStockMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &gormStockMovementRepository{db: db}
+}
+
+func (r *gormStockMovementRepository) List(filter StockMovementFilter) ([]StockMovementEntry, int64, error) {
+	// остаток считается оконной функцией по всей истории, а период и тип
+	// применяются уже к результату, чтобы баланс не зависел от них
+	ledger := r.scope(filter).Model(&models.StockMovement{}).
+		Select("stock_movements.*, SUM(delta) OVER (ORDER BY created_at, id) AS balance")
+
+	query := r.db.Table("(?) AS ledger", ledger)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []StockMovementEntry
+	if err := query.Order("created_at DESC, id DESC").
+		Offset(filter.Offset).Limit(filter.Limit).
+		Scan(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+func (r *gormStockMovementRepository) Balance(filter StockMovementFilter) (int64, error) {
+	var balance int64
+	err := r.scope(filter).Model(&models.StockMovement{}).
+		Select("COALESCE(SUM(delta), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *gormStockMovementRepository) scope(filter StockMovementFilter) *gorm.DB {
+	db := r.db.Where("medicine_id = ?", filter.MedicineID)
+	if filter.VariantID != nil {
+		db = db.Where("variant_id = ?", *filter.VariantID)
+	}
+	if filter.WarehouseID != nil {
+		db = db.Where("warehouse_id = ?", *filter.WarehouseID)
+	}
+	return db
+}
+
+// movementFor дополняет движение ссылками на партию, её фасовку, лекарство и филиал.
+func movementFor(batch *models.StockBatch, movement models.StockMovement) models.StockMovement {
+	movement.BatchID = batch.ID
+	movement.VariantID = batch.VariantID
+	movement.MedicineID = batch.MedicineID
+	movement.WarehouseID = batch.WarehouseID
+	return movement
+}
+
+func recordStockMovements(tx *gorm.DB, movements ...models.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	return tx.Create(&movements).Error
+}
+
+// MigrateStockLedger записывает входящий остаток корректировкой для партий,
+// по которым в журнале ещё нет движений, чтобы остаток партии совпадал с
+// суммой её движений. Вызывается после MigrateWarehouses.
+func MigrateStockLedger(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO stock_movements
+			(created_at, batch_id, variant_id, medicine_id, warehouse_id, type, delta, reason)
+		SELECT b.created_at, b.id, b.variant_id, b.medicine_id, b.warehouse_id, ?, b.quantity, 'opening balance'
+		FROM stock_batches b
+		WHERE NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.batch_id = b.id)`,
+		models.StockMovementAdjustment).Error
+}
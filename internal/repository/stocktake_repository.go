@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StocktakeFilter - выборка инвентаризаций. Нулевые значения не фильтруют.
+type StocktakeFilter struct {
+	Status      models.StocktakeStatus
+	WarehouseID *uint
+}
+
+type StocktakeRepository interface {
+	// Create открывает инвентаризацию филиала со снимком остатков его партий,
+	// включая нулевые (если medicineIDs не пуст - только партий этих лекарств).
+	Create(stocktake *models.Stocktake, medicineIDs []uint) error
+	GetByID(id uint) (*models.Stocktake, error)
+	List(filter StocktakeFilter) ([]models.Stocktake, error)
+	// SaveCounts записывает фактические количества по строкам открытой инвентаризации.
+	SaveCounts(id uint, counts map[uint]int, actorID *uint) (*models.Stocktake, error)
+	// Apply в одной транзакции записывает расхождения подсчитанных строк в журнал
+	// движений, пересчитывает остатки и закрывает инвентаризацию.
+	Apply(id uint, actorID *uint) (*models.Stocktake, error)
+	Cancel(id uint) (*models.Stocktake, error)
+}
+
+type gormStocktakeRepository struct {
+	db *gorm.DB
+}
+
+func NewStocktakeRepository(db *gorm.DB) StocktakeRepository {
+	return &gormStocktakeRepository{db: db}
+}
+
+func (r *gormStocktakeRepository) Create(stocktake *models.Stocktake, medicineIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// блокировка филиала не даёт открыть две инвентаризации одновременно
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&models.Warehouse{}, stocktake.WarehouseID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrWarehouseNotFound
+			}
+			return err
+		}
+
+		var open int64
+		if err := tx.Model(&models.Stocktake{}).
+			Where("warehouse_id = ? AND status = ?", stocktake.WarehouseID, models.StocktakeOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errs.ErrStocktakeInProgress
+		}
+
+		// считаются все партии филиала, в том числе просроченные и отозванные (они
+		// физически лежат на полке до списания) и пустые по учёту: найденный на полке
+		// товар такой серии иначе нельзя было бы вернуть в остаток
+		db := tx.Where("warehouse_id = ?", stocktake.WarehouseID)
+		if len(medicineIDs) > 0 {
+			db = db.Where("medicine_id IN ?", medicineIDs)
+		}
+		var batches []models.StockBatch
+		if err := db.Order("medicine_id, variant_id, expires_at NULLS LAST, id").Find(&batches).Error; err != nil {
+			return err
+		}
+		if len(batches) == 0 {
+			return errs.ErrEmptyStocktake
+		}
+
+		stocktake.Lines = make([]models.StocktakeLine, 0, len(batches))
+		for _, batch := range batches {
+			stocktake.Lines = append(stocktake.Lines, models.StocktakeLine{
+				BatchID:          batch.ID,
+				VariantID:        batch.VariantID,
+				MedicineID:       batch.MedicineID,
+				LotNumber:        batch.LotNumber,
+				ExpiresAt:        batch.ExpiresAt,
+				RecallID:         batch.RecallID,
+				ExpectedQuantity: int(batch.Quantity),
+			})
+		}
+		return tx.Create(stocktake).Error
+	})
+}
+
+func (r *gormStocktakeRepository) GetByID(id uint) (*models.Stocktake, error) {
+	var stocktake models.Stocktake
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Lines.Medicine").
+		First(&stocktake, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrStocktakeNotFound
+		}
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+func (r *gormStocktakeRepository) List(filter StocktakeFilter) ([]models.Stocktake, error) {
+	db := r.db
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.WarehouseID != nil {
+		db = db.Where("warehouse_id = ?", *filter.WarehouseID)
+	}
+
+	var stocktakes []models.Stocktake
+	if err := db.Order("created_at DESC, id DESC").Find(&stocktakes).Error; err != nil {
+		return nil, err
+	}
+	return stocktakes, nil
+}
+
+func (r *gormStocktakeRepository) SaveCounts(id uint, counts map[uint]int, actorID *uint) (*models.Stocktake, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOpenStocktake(tx, id); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for lineID, counted := range counts {
+			res := tx.Model(&models.StocktakeLine{}).
+				Where("id = ? AND stocktake_id = ?", lineID, id).
+				Updates(map[string]any{
+					"counted_quantity": counted,
+					"counted_by":       actorID,
+					"counted_at":       now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errs.ErrInvalidStocktakeCount
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *gormStocktakeRepository) Apply(id uint, actorID *uint) (*models.Stocktake, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var lines []models.StocktakeLine
+		if err := tx.Where("stocktake_id = ? AND counted_quantity IS NOT NULL AND counted_quantity <> expected_quantity", id).
+			Order("id").Find(&lines).Error; err != nil {
+			return err
+		}
+
+		if len(lines) > 0 {
+			variantIDs := make([]uint, 0, len(lines))
+			batchIDs := make([]uint, 0, len(lines))
+			for _, line := range lines {
+				variantIDs = append(variantIDs, line.VariantID)
+				batchIDs = append(batchIDs, line.BatchID)
+			}
+			if _, err := lockVariants(tx, variantIDs...); err != nil {
+				return err
+			}
+			var batches []models.StockBatch
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id IN ?", batchIDs).Order("id").
+				Find(&batches).Error; err != nil {
+				return err
+			}
+			byID := make(map[uint]*models.StockBatch, len(batches))
+			for i := range batches {
+				byID[batches[i].ID] = &batches[i]
+			}
+
+			movements := make([]models.StockMovement, 0, len(lines))
+			for _, line := range lines {
+				batch, ok := byID[line.BatchID]
+				if !ok {
+					return errs.ErrBatchNotFound
+				}
+
+				// расхождение считается от снимка, поэтому продажи и поступления
+				// после открытия инвентаризации сохраняются; остаток не уходит ниже нуля
+				delta := max(*line.CountedQuantity-line.ExpectedQuantity, -int(batch.Quantity))
+				if delta == 0 {
+					continue
+				}
+				if err := tx.Model(batch).Update("quantity", int(batch.Quantity)+delta).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&line).Update("adjustment", delta).Error; err != nil {
+					return err
+				}
+				movements = append(movements, movementFor(batch, models.StockMovement{
+					Type:         models.StockMovementAdjustment,
+					Delta:        delta,
+					Reason:       "stocktake",
+					ActorID:      actorID,
+					DocumentType: models.StockDocumentStocktake,
+					DocumentID:   &stocktake.ID,
+				}))
+			}
+			if err := recordStockMovements(tx, movements...); err != nil {
+				return err
+			}
+			if err := syncVariantStock(tx, time.Now(), variantIDs...); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(stocktake).Updates(map[string]any{
+			"status":     models.StocktakeApplied,
+			"applied_by": actorID,
+			"applied_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *gormStocktakeRepository) Cancel(id uint) (*models.Stocktake, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.Model(stocktake).Update("status", models.StocktakeCanceled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func lockOpenStocktake(tx *gorm.DB, id uint) (*models.Stocktake, error) {
+	var stocktake models.Stocktake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stocktake, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrStocktakeNotFound
+		}
+		return nil, err
+	}
+	if stocktake.Status != models.StocktakeOpen {
+		return nil, errs.ErrStocktakeNotOpen
+	}
+	return &stocktake, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SupplierRepository interface {
+	Create(supplier *models.Supplier) error
+	GetByID(id uint) (*models.Supplier, error)
+	List() ([]models.Supplier, error)
+	Update(supplier *models.Supplier) error
+}
+
+type gormSupplierRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &gormSupplierRepository{db: db}
+}
+
+func (r *gormSupplierRepository) Create(supplier *models.Supplier) error {
+	return r.db.Create(supplier).Error
+}
+
+func (r *gormSupplierRepository) GetByID(id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	if err := r.db.First(&supplier, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrSupplierNotFound
+		}
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *gormSupplierRepository) List() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	if err := r.db.Order("name, id").Find(&suppliers).Error; err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+func (r *gormSupplierRepository) Update(supplier *models.Supplier) error {
+	return r.db.Save(supplier).Error
+}
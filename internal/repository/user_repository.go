@@ -9,6 +9,7 @@ import (
 type UserRepository interface {
 	Create(user *models.User) error
 	GetByID(id uint) (*models.User, error)
+	GetByEmail(email string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id uint) error
 	List() ([]models.User, error)
@@ -37,6 +38,15 @@ func (r *gormUserRepository) GetByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *gormUserRepository) GetByEmail(email string) (*models.User, error) {
+	var user models.User
+
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *gormUserRepository) Update(user *models.User) error {
 
 	return r.db.Save(&user).Error
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WarehouseStock - продаваемый остаток фасовки в филиале.
+type WarehouseStock struct {
+	WarehouseID uint
+	VariantID   uint
+	Quantity    uint
+}
+
+type WarehouseRepository interface {
+	Create(warehouse *models.Warehouse) error
+	GetByID(id uint) (*models.Warehouse, error)
+	List(onlyActive bool) ([]models.Warehouse, error)
+	Update(warehouse *models.Warehouse) error
+	// Stock возвращает продаваемые остатки вариантов по филиалам.
+	Stock(variantIDs []uint) ([]WarehouseStock, error)
+	// MedicineStock - то же для всех фасовок лекарства.
+	MedicineStock(medicineID uint) ([]WarehouseStock, error)
+	// WarehouseStockLevels - продаваемые остатки всех фасовок в одном филиале.
+	WarehouseStockLevels(warehouseID uint) ([]WarehouseStock, error)
+	Transfer(transfer *models.StockTransfer) error
+	ListTransfers(warehouseID *uint) ([]models.StockTransfer, error)
+}
+
+type gormWarehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) WarehouseRepository {
+	return &gormWarehouseRepository{db: db}
+}
+
+func (r *gormWarehouseRepository) Create(warehouse *models.Warehouse) error {
+	return r.db.Create(warehouse).Error
+}
+
+func (r *gormWarehouseRepository) GetByID(id uint) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	if err := r.db.First(&warehouse, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *gormWarehouseRepository) List(onlyActive bool) ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	db := r.db.Order("priority, id")
+	if onlyActive {
+		db = db.Where("is_active")
+	}
+	if err := db.Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (r *gormWarehouseRepository) Update(warehouse *models.Warehouse) error {
+	return r.db.Save(warehouse).Error
+}
+
+func (r *gormWarehouseRepository) Stock(variantIDs []uint) ([]WarehouseStock, error) {
+	if len(variantIDs) == 0 {
+		return nil, nil
+	}
+	return r.stock(r.db.Where("variant_id IN ?", variantIDs))
+}
+
+func (r *gormWarehouseRepository) MedicineStock(medicineID uint) ([]WarehouseStock, error) {
+	return r.stock(r.db.Where("medicine_id = ?", medicineID))
+}
+
+func (r *gormWarehouseRepository) WarehouseStockLevels(warehouseID uint) ([]WarehouseStock, error) {
+	return r.stock(r.db.Where("warehouse_id = ?", warehouseID))
+}
+
+func (r *gormWarehouseRepository) stock(db *gorm.DB) ([]WarehouseStock, error) {
+	var result []WarehouseStock
+	err := db.Model(&models.StockBatch{}).
+		Select("warehouse_id, variant_id, SUM(quantity) AS quantity").
+		Where("quantity > 0").
+		Where(sellableBatchCondition, time.Now()).
+		Group("warehouse_id, variant_id").
+		Order("warehouse_id, variant_id").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Transfer списывает партии фасовки в филиале-отправителе по FEFO и зачисляет
+// их получателю: в партию с той же серией и сроком или в новую. Общий остаток
+// фасовки не меняется.
+func (r *gormWarehouseRepository) Transfer(transfer *models.StockTransfer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.MedicineVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, transfer.VariantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrVariantNotFound
+			}
+			return err
+		}
+		transfer.MedicineID = variant.MedicineID
+
+		var source []models.StockBatch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("variant_id = ? AND warehouse_id = ? AND quantity > 0", variant.ID, transfer.FromWarehouseID).
+			Where(sellableBatchCondition, time.Now()).
+			Order("expires_at NULLS LAST, id").
+			Find(&source).Error; err != nil {
+			return err
+		}
+
+		var available uint
+		for _, batch := range source {
+			available += batch.Quantity
+		}
+		if available < transfer.Quantity {
+			return fmt.Errorf("%w: %s", errs.ErrInsufficientStock, variant.SKU)
+		}
+
+		if err := tx.Omit(clause.Associations).Create(transfer).Error; err != nil {
+			return err
+		}
+
+		need := transfer.Quantity
+		for i := range source {
+			if need == 0 {
+				break
+			}
+			from := &source[i]
+			take := min(need, from.Quantity)
+			need -= take
+
+			if err := tx.Model(from).Update("quantity", from.Quantity-take).Error; err != nil {
+				return err
+			}
+			to, err := receiveTransferredBatch(tx, from, transfer.ToWarehouseID, take)
+			if err != nil {
+				return err
+			}
+
+			line := models.StockTransferLine{
+				TransferID:  transfer.ID,
+				FromBatchID: from.ID,
+				ToBatchID:   to.ID,
+				LotNumber:   from.LotNumber,
+				ExpiresAt:   from.ExpiresAt,
+				Quantity:    take,
+			}
+			if err := tx.Create(&line).Error; err != nil {
+				return err
+			}
+			transfer.Lines = append(transfer.Lines, line)
+
+			movement := models.StockMovement{
+				Type:         models.StockMovementTransfer,
+				Reason:       transfer.Comment,
+				ActorID:      transfer.CreatedBy,
+				DocumentType: models.StockDocumentTransfer,
+				DocumentID:   &transfer.ID,
+			}
+			out, in := movement, movement
+			out.Delta = -int(take)
+			in.Delta = int(take)
+			if err := recordStockMovements(tx, movementFor(from, out), movementFor(to, in)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// receiveTransferredBatch зачисляет quantity в филиал warehouseID в партию с той
+// же серией и сроком годности, что и from, создавая её при необходимости.
+func receiveTransferredBatch(tx *gorm.DB, from *models.StockBatch, warehouseID uint, quantity uint) (*models.StockBatch, error) {
+	var to models.StockBatch
+	db := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id = ? AND warehouse_id = ? AND lot_number = ? AND recall_id IS NULL",
+			from.VariantID, warehouseID, from.LotNumber)
+	if from.ExpiresAt == nil {
+		db = db.Where("expires_at IS NULL")
+	} else {
+		db = db.Where("expires_at = ?", *from.ExpiresAt)
+	}
+
+	err := db.First(&to).Error
+	if err == nil {
+		to.Quantity += quantity
+		to.ReceivedQuantity += quantity
+		if err := tx.Model(&to).Updates(map[string]any{
+			"quantity":          to.Quantity,
+			"received_quantity": to.ReceivedQuantity,
+		}).Error; err != nil {
+			return nil, err
+		}
+		return &to, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	to = models.StockBatch{
+		VariantID:        from.VariantID,
+		MedicineID:       from.MedicineID,
+		WarehouseID:      warehouseID,
+		LotNumber:        from.LotNumber,
+		ExpiresAt:        from.ExpiresAt,
+		Quantity:         quantity,
+		ReceivedQuantity: quantity,
+	}
+	if err := tx.Omit(clause.Associations).Create(&to).Error; err != nil {
+		return nil, err
+	}
+	return &to, nil
+}
+
+func (r *gormWarehouseRepository) ListTransfers(warehouseID *uint) ([]models.StockTransfer, error) {
+	var transfers []models.StockTransfer
+	db := r.db.Preload("Lines").Order("created_at DESC, id DESC")
+	if warehouseID != nil {
+		db = db.Where("from_warehouse_id = ? OR to_warehouse_id = ?", *warehouseID, *warehouseID)
+	}
+	if err := db.Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// MigrateWarehouses заводит основной филиал, если филиалов ещё нет, и относит
+// к нему партии без филиала. Вызывается после MigrateStockBatches.
+func MigrateWarehouses(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Warehouse{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			warehouse := models.Warehouse{
+				Code:     models.DefaultWarehouseCode,
+				Name:     "Основной склад",
+				IsActive: true,
+			}
+			if err := tx.Create(&warehouse).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`UPDATE stock_batches SET warehouse_id = (
+				SELECT id FROM warehouses WHERE deleted_at IS NULL ORDER BY (code = ?) DESC, priority, id LIMIT 1)
+			WHERE warehouse_id IS NULL OR warehouse_id = 0`, models.DefaultWarehouseCode).Error
+	})
+}
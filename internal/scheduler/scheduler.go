@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job - периодическая задача, которую планировщик запускает раз в Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs   []Job
+	logger *slog.Logger
+	wg     sync.WaitGroup
+}
+
+func New(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger.With("layer", "scheduler")}
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start запускает все задачи в отдельных горутинах. Задачи останавливаются при отмене ctx,
+// дождаться их завершения можно через Wait.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	s.logger.Info("job scheduled",
+		"job", job.Name,
+		"interval", job.Interval.String(),
+	)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("job stopped",
+				"job", job.Name,
+			)
+			return
+		case <-ticker.C:
+			s.run(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	started := time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("job panicked",
+				"job", job.Name,
+				"panic", r,
+			)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("job failed",
+			"job", job.Name,
+			"duration", time.Since(started).String(),
+			"error", err,
+		)
+		return
+	}
+
+	s.logger.Info("job finished",
+		"job", job.Name,
+		"duration", time.Since(started).String(),
+	)
+}
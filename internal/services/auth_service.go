@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"team-pharmacy/internal/auth"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type AuthService interface {
+	Register(req dto.RegisterRequest) (*dto.TokenResponse, error)
+	Login(req dto.LoginRequest) (*dto.TokenResponse, error)
+	Refresh(req dto.RefreshRequest) (*dto.TokenResponse, error)
+	Logout(req dto.LogoutRequest) error
+	Authenticate(accessToken string) (*auth.Claims, error)
+	EnsureAdmin(email, password string) error
+}
+
+type authService struct {
+	users         repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	tokens        *auth.TokenManager
+	refreshTTL    time.Duration
+}
+
+func NewAuthService(users repository.UserRepository, refreshTokens repository.RefreshTokenRepository,
+	tokens *auth.TokenManager, refreshTTL time.Duration) AuthService {
+
+	return &authService{users: users, refreshTokens: refreshTokens, tokens: tokens, refreshTTL: refreshTTL}
+}
+
+func (s *authService) Register(req dto.RegisterRequest) (*dto.TokenResponse, error) {
+	email := strings.TrimSpace(req.Email)
+
+	_, err := s.users.GetByEmail(email)
+	if err == nil {
+		return nil, errs.ErrUserAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		FullName:       strings.TrimSpace(req.FullName),
+		Email:          email,
+		Phone:          strings.TrimSpace(req.Phone),
+		DefaultAddress: strings.TrimSpace(req.DefaultAddress),
+		PasswordHash:   string(hash),
+		Role:           models.RoleCustomer,
+	}
+
+	if err := s.users.Create(user); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+func (s *authService) Login(req dto.LoginRequest) (*dto.TokenResponse, error) {
+	user, err := s.users.GetByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if user.PasswordHash == "" {
+		return nil, errs.ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, errs.ErrInvalidCredentials
+	}
+
+	return s.issueTokens(user)
+}
+
+// Refresh меняет refresh-токен на новую пару токенов. Старый токен отзывается;
+// повторное использование уже отозванного токена отзывает все сессии пользователя.
+func (s *authService) Refresh(req dto.RefreshRequest) (*dto.TokenResponse, error) {
+	stored, err := s.refreshTokens.GetByHash(auth.HashToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		if err := s.refreshTokens.RevokeAllForUser(stored.UserID); err != nil {
+			return nil, err
+		}
+		return nil, errs.ErrInvalidToken
+	}
+	if !stored.IsActiveAt(time.Now()) {
+		return nil, errs.ErrInvalidToken
+	}
+
+	user, err := s.users.GetByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	refreshToken, record, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.Rotate(stored.ID, record); err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(user, refreshToken)
+}
+
+func (s *authService) Logout(req dto.LogoutRequest) error {
+	stored, err := s.refreshTokens.GetByHash(auth.HashToken(req.RefreshToken))
+	if err != nil {
+		return err
+	}
+
+	if req.All {
+		return s.refreshTokens.RevokeAllForUser(stored.UserID)
+	}
+	return s.refreshTokens.Revoke(stored.ID)
+}
+
+func (s *authService) Authenticate(accessToken string) (*auth.Claims, error) {
+	claims, err := s.tokens.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, errs.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// EnsureAdmin создаёт администратора с указанными email и паролем, если такого
+// email ещё нет. Вызывается при старте приложения. Существующий пользователь не
+// повышается до admin: адрес мог заранее занять покупатель, и он получил бы роль
+// со своим паролем, поэтому в этом случае запуск прерывается.
+func (s *authService) EnsureAdmin(email, password string) error {
+	user, err := s.users.GetByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if user != nil {
+		if user.Role == models.RoleAdmin {
+			return nil
+		}
+		return fmt.Errorf("user %s already exists and is not an admin", email)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.users.Create(&models.User{
+		FullName:     "Administrator",
+		Email:        email,
+		PasswordHash: string(hash),
+		Role:         models.RoleAdmin,
+	})
+}
+
+func (s *authService) issueTokens(user *models.User) (*dto.TokenResponse, error) {
+	refreshToken, record, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.Create(record); err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(user, refreshToken)
+}
+
+func (s *authService) newRefreshToken(userID uint) (string, *models.RefreshToken, error) {
+	token, err := auth.RandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &models.RefreshToken{
+		UserID:    userID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
+
+func (s *authService) tokenResponse(user *models.User, refreshToken string) (*dto.TokenResponse, error) {
+	accessToken, err := s.tokens.IssueAccessToken(user.ID, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		UserID:       user.ID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokens.AccessTTL().Seconds()),
+	}, nil
+}
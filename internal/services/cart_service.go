@@ -18,26 +18,30 @@ type CartService interface {
 	UpdateItem(userID, itemID uint, req *dto.UpdateCartItemRequest) (*dto.CartItemResponse, error)
 	GetCartWithItems(userID uint) (*dto.CartResponse, error)
 	DeleteItem(userID, itemID uint) error
+	SwapItem(userID, itemID uint, req *dto.SwapCartItemRequest) (*dto.CartResponse, error)
 
 	ClearCart(userID uint) error
 }
 
 type cartService struct {
-	carts    repository.CartRepository
-	users    repository.UserRepository
-	medicine repository.MedicineRepository
-	logger   *slog.Logger
+	carts         repository.CartRepository
+	users         repository.UserRepository
+	medicine      repository.MedicineRepository
+	prescriptions repository.PrescriptionRepository
+	logger        *slog.Logger
 }
 
 func NewCartService(cartRepo repository.CartRepository,
 	userRepo repository.UserRepository,
 	medicineRepo repository.MedicineRepository,
+	prescriptionRepo repository.PrescriptionRepository,
 	logger *slog.Logger,
 ) CartService {
 	return &cartService{
-		carts:    cartRepo,
-		users:    userRepo,
-		medicine: medicineRepo,
+		carts:         cartRepo,
+		users:         userRepo,
+		medicine:      medicineRepo,
+		prescriptions: prescriptionRepo,
 		logger: logger.With("layer", "service",
 			"entity", "cart",
 		)}
@@ -141,21 +145,41 @@ func (s *cartService) CreateItem(userID uint, req *dto.AddCartItemRequest) (*dto
 		return nil, err
 	}
 
-	if int(medicine.StockQuantity) < req.Quantity {
+	if err := ensurePrescriptions(s.prescriptions, userID, []*models.Medicine{medicine}); err != nil {
+		s.logger.Warn("prescription check failed",
+			"user_id", userID,
+			"medicine_id", medicine.ID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	variant, err := pickVariant(medicine, req.VariantID)
+	if err != nil {
+		s.logger.Warn("variant not resolved",
+			"medicine_id", medicine.ID,
+			"variant_id", req.VariantID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	if int(variant.StockQuantity) < req.Quantity {
 		s.logger.Warn("not enough stock",
 			"medicine_id", req.MedicineID,
-			"stock", medicine.StockQuantity,
+			"variant_id", variant.ID,
+			"stock", variant.StockQuantity,
 			"requested", req.Quantity,
 		)
 
 		return nil, errors.New("не достаточно лекарств на складе")
 	}
 
-	existsItem, err := s.carts.GetItem(cart.ID, medicine.ID)
+	existsItem, err := s.carts.GetItem(cart.ID, variant.ID)
 	if err != nil {
 		s.logger.Error("failed to check existing item",
 			"cart_id", cart.ID,
-			"medicine_id", medicine.ID,
+			"variant_id", variant.ID,
 			"error", err,
 		)
 		return nil, err
@@ -163,13 +187,14 @@ func (s *cartService) CreateItem(userID uint, req *dto.AddCartItemRequest) (*dto
 
 	if existsItem != nil {
 		existsItem.Quantity += req.Quantity
-		if existsItem.Quantity > int(medicine.StockQuantity) {
+		if existsItem.Quantity > int(variant.StockQuantity) {
 			s.logger.Warn("stock limit exceeded",
 				"medicine_id", medicine.ID,
+				"variant_id", variant.ID,
 			)
 			return nil, errors.New("stock limit exceeded")
 		}
-		existsItem.PricePerUnit = int64(medicine.Price)
+		existsItem.PricePerUnit = int64(variant.Price)
 		if err := s.carts.UpdateItem(existsItem); err != nil {
 			s.logger.Error("failed to update cart item ",
 				"item_id", existsItem.ID,
@@ -188,8 +213,9 @@ func (s *cartService) CreateItem(userID uint, req *dto.AddCartItemRequest) (*dto
 	newItem := models.CartItem{
 		CartID:       cart.ID,
 		MedicineID:   medicine.ID,
+		VariantID:    &variant.ID,
 		Quantity:     req.Quantity,
-		PricePerUnit: int64(medicine.Price),
+		PricePerUnit: int64(variant.Price),
 	}
 
 	if err := s.carts.CreateItem(&newItem); err != nil {
@@ -269,22 +295,13 @@ func (s *cartService) UpdateItem(userID, itemID uint, req *dto.UpdateCartItemReq
 		return nil, errs.ErrItemNotFound
 	}
 
-	medicine, err := s.medicine.GetByID(item.MedicineID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("medicine not found",
-				"user_id", userID,
-				"medicine_id", item.MedicineID,
-			)
-			return nil, errs.ErrMedicineNotFound
-		}
-
-		s.logger.Error("failed to get medicine",
+	variant := item.Variant
+	if variant == nil {
+		s.logger.Warn("variant not found",
 			"user_id", userID,
-			"medicine_id", item.MedicineID,
-			"error", err,
+			"item_id", item.ID,
 		)
-		return nil, err
+		return nil, errs.ErrVariantNotFound
 	}
 
 	if req.Quantity <= 0 {
@@ -303,27 +320,30 @@ func (s *cartService) UpdateItem(userID, itemID uint, req *dto.UpdateCartItemReq
 		return nil, nil
 	}
 
-	if req.Quantity > int(medicine.StockQuantity) {
+	if req.Quantity > int(variant.StockQuantity) {
 		s.logger.Warn("stock limit exceeded",
 			"user_id", userID,
 			"item_id", item.ID,
 			"requested", req.Quantity,
-			"available", medicine.StockQuantity,
+			"available", variant.StockQuantity,
 		)
 		return nil, errors.New("stock limit exceeded")
 	}
 
-	lineTotal := int64(req.Quantity) * int64(medicine.Price)
+	lineTotal := int64(req.Quantity) * int64(variant.Price)
 	newItem := dto.CartItemResponse{
 		ItemID:       item.ID,
 		MedicineID:   item.MedicineID,
+		VariantID:    variant.ID,
+		SKU:          variant.SKU,
 		Quantity:     req.Quantity,
-		PricePerUnit: int64(medicine.Price),
+		PricePerUnit: int64(variant.Price),
 		LineTotal:    lineTotal,
 	}
 
 	item.Quantity = req.Quantity
-	item.PricePerUnit = int64(medicine.Price)
+	item.PricePerUnit = int64(variant.Price)
+	item.Variant = nil
 	if err := s.carts.UpdateItem(item); err != nil {
 
 		s.logger.Error("failed to update cart item",
@@ -339,7 +359,7 @@ func (s *cartService) UpdateItem(userID, itemID uint, req *dto.UpdateCartItemReq
 		"quantity", req.Quantity,
 		"line_total", lineTotal,
 	)
-	return newItem, nil
+	return &newItem, nil
 }
 
 func (s *cartService) GetCartWithItems(userID uint) (*dto.CartResponse, error) {
@@ -383,13 +403,19 @@ func (s *cartService) GetCartWithItems(userID uint) (*dto.CartResponse, error) {
 		lineTotal := int64(it.Quantity) * it.PricePerUnit
 		total += lineTotal
 
-		items = append(items, dto.CartItemResponse{
+		item := dto.CartItemResponse{
 			ItemID:       it.ID,
 			MedicineID:   it.MedicineID,
 			Quantity:     it.Quantity,
 			PricePerUnit: it.PricePerUnit,
 			LineTotal:    lineTotal,
-		})
+			RecallID:     it.RecallID,
+		}
+		if it.Variant != nil {
+			item.VariantID = it.Variant.ID
+			item.SKU = it.Variant.SKU
+		}
+		items = append(items, item)
 	}
 
 	resp := dto.CartResponse{
@@ -481,6 +507,116 @@ func (s *cartService) DeleteItem(userID, itemID uint) error {
 	return errs.ErrItemNotFound
 }
 
+// SwapItem заменяет лекарство в позиции корзины на его аналог с тем же количеством.
+func (s *cartService) SwapItem(userID, itemID uint, req *dto.SwapCartItemRequest) (*dto.CartResponse, error) {
+	s.logger.Info("swap cart item started",
+		"user_id", userID,
+		"item_id", itemID,
+		"medicine_id", req.MedicineID,
+	)
+
+	if userID == 0 || itemID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	cart, err := s.carts.GetCartWithItems(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrCartNotFound
+		}
+		s.logger.Error("failed to get cart with items",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	var item *models.CartItem
+	for i := range cart.Items {
+		if cart.Items[i].ID == itemID {
+			item = &cart.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, errs.ErrItemNotFound
+	}
+	if item.MedicineID == req.MedicineID {
+		return s.GetCartWithItems(userID)
+	}
+
+	analogs, err := s.medicine.Analogs(item.MedicineID)
+	if err != nil {
+		s.logger.Error("failed to get analogs",
+			"medicine_id", item.MedicineID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	var analog *models.Medicine
+	for i := range analogs {
+		if analogs[i].ID == req.MedicineID {
+			analog = &analogs[i]
+			break
+		}
+	}
+	if analog == nil {
+		s.logger.Warn("medicine is not an analog",
+			"item_medicine_id", item.MedicineID,
+			"medicine_id", req.MedicineID,
+		)
+		return nil, errs.ErrNotAnAnalog
+	}
+
+	if err := ensurePrescriptions(s.prescriptions, userID, []*models.Medicine{analog}); err != nil {
+		s.logger.Warn("prescription check failed",
+			"user_id", userID,
+			"medicine_id", analog.ID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	variant, err := pickVariant(analog, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantityInCart := 0
+	for _, it := range cart.Items {
+		if it.VariantID != nil && *it.VariantID == variant.ID {
+			quantityInCart += it.Quantity
+		}
+	}
+	if quantityInCart+item.Quantity > int(variant.StockQuantity) {
+		s.logger.Warn("not enough stock",
+			"medicine_id", analog.ID,
+			"variant_id", variant.ID,
+			"stock", variant.StockQuantity,
+			"requested", quantityInCart+item.Quantity,
+		)
+		return nil, errs.ErrInsufficientStock
+	}
+
+	if err := s.carts.SwapItem(item, variant); err != nil {
+		s.logger.Error("failed to swap cart item",
+			"item_id", item.ID,
+			"variant_id", variant.ID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	s.logger.Info("cart item swapped",
+		"user_id", userID,
+		"item_id", itemID,
+		"medicine_id", analog.ID,
+	)
+
+	return s.GetCartWithItems(userID)
+}
+
 func (s *cartService) ClearCart(userID uint) error {
 	s.logger.Info("clear cart started",
 		"user_id", userID,
@@ -521,3 +657,24 @@ func (s *cartService) ClearCart(userID uint) error {
 	return nil
 
 }
+
+// pickVariant выбирает вариант лекарства: указанный явно или единственный.
+func pickVariant(medicine *models.Medicine, variantID *uint) (*models.MedicineVariant, error) {
+	if variantID != nil {
+		for i := range medicine.Variants {
+			if medicine.Variants[i].ID == *variantID {
+				return &medicine.Variants[i], nil
+			}
+		}
+		return nil, errs.ErrVariantNotFound
+	}
+
+	switch len(medicine.Variants) {
+	case 0:
+		return nil, errs.ErrVariantNotFound
+	case 1:
+		return &medicine.Variants[0], nil
+	default:
+		return nil, errs.ErrVariantRequired
+	}
+}
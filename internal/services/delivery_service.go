@@ -0,0 +1,304 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+)
+
+type DeliveryService interface {
+	CreateZone(req dto.DeliveryZoneCreate) (*models.DeliveryZone, error)
+	UpdateZone(id uint, req dto.DeliveryZoneUpdate) (*models.DeliveryZone, error)
+	ListZones(includeInactive bool) ([]models.DeliveryZone, error)
+	// ResolveZone находит зону по почтовому индексу из адреса или по координатам,
+	// без внешнего геокодера. Индекс имеет приоритет над координатами.
+	ResolveZone(address string, point *models.GeoPoint) (*models.DeliveryZone, error)
+	Quote(query dto.DeliveryQuoteQuery) (*dto.DeliveryQuoteResponse, error)
+
+	CreateSlots(zoneID uint, req dto.DeliverySlotsCreate) ([]dto.DeliverySlotResponse, error)
+	UpdateSlot(id uint, req dto.DeliverySlotUpdate) (*dto.DeliverySlotResponse, error)
+	// AvailableSlots - слоты зоны, на которые ещё можно записаться.
+	AvailableSlots(zoneID uint, query dto.DeliverySlotQuery) ([]dto.DeliverySlotResponse, error)
+	GetSlot(id uint) (*models.DeliverySlot, error)
+}
+
+type deliveryService struct {
+	zones repository.DeliveryZoneRepository
+	slots repository.DeliverySlotRepository
+}
+
+func NewDeliveryService(zones repository.DeliveryZoneRepository, slots repository.DeliverySlotRepository) DeliveryService {
+	return &deliveryService{zones: zones, slots: slots}
+}
+
+// defaultSlotDays - на сколько дней вперёд показываются слоты по умолчанию.
+const defaultSlotDays = 7
+
+// postalCodePattern - шестизначный почтовый индекс, отделённый от соседних цифр.
+var postalCodePattern = regexp.MustCompile(`(?:^|\D)(\d{6})(?:\D|$)`)
+
+func (s *deliveryService) CreateZone(req dto.DeliveryZoneCreate) (*models.DeliveryZone, error) {
+	zone := &models.DeliveryZone{
+		Name:             strings.TrimSpace(req.Name),
+		PostalCodes:      normalizePostalCodes(req.PostalCodes),
+		Polygon:          toGeoPoints(req.Polygon),
+		Fee:              req.Fee,
+		MinOrderValue:    req.MinOrderValue,
+		FreeDeliveryFrom: req.FreeDeliveryFrom,
+		Priority:         req.Priority,
+		IsActive:         true,
+	}
+	if err := validateDeliveryZone(zone); err != nil {
+		return nil, err
+	}
+	if err := s.zones.Create(zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (s *deliveryService) UpdateZone(id uint, req dto.DeliveryZoneUpdate) (*models.DeliveryZone, error) {
+	zone, err := s.zones.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		zone.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.PostalCodes != nil {
+		zone.PostalCodes = normalizePostalCodes(req.PostalCodes)
+	}
+	if req.Polygon != nil {
+		zone.Polygon = toGeoPoints(req.Polygon)
+	}
+	if req.Fee != nil {
+		zone.Fee = *req.Fee
+	}
+	if req.MinOrderValue != nil {
+		zone.MinOrderValue = *req.MinOrderValue
+	}
+	if req.FreeDeliveryFrom != nil {
+		zone.FreeDeliveryFrom = req.FreeDeliveryFrom
+	}
+	if req.ClearFreeDelivery {
+		zone.FreeDeliveryFrom = nil
+	}
+	if req.Priority != nil {
+		zone.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+
+	if err := validateDeliveryZone(zone); err != nil {
+		return nil, err
+	}
+	if err := s.zones.Update(zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (s *deliveryService) ListZones(includeInactive bool) ([]models.DeliveryZone, error) {
+	return s.zones.List(!includeInactive)
+}
+
+func (s *deliveryService) ResolveZone(address string, point *models.GeoPoint) (*models.DeliveryZone, error) {
+	zones, err := s.zones.List(true)
+	if err != nil {
+		return nil, err
+	}
+
+	postalCode := ""
+	if match := postalCodePattern.FindStringSubmatch(address); match != nil {
+		postalCode = match[1]
+	}
+
+	// координаты присылает клиент, поэтому они не могут перебить индекс адреса:
+	// зона по индексу выбирается первой, а точка вне её многоугольника отклоняется
+	for i := range zones {
+		zone := &zones[i]
+		if !zone.HasPostalCode(postalCode) {
+			continue
+		}
+		if point != nil && len(zone.Polygon) > 0 && !zone.Contains(*point) {
+			return nil, errs.ErrDeliveryPointMismatch
+		}
+		return zone, nil
+	}
+
+	if point == nil {
+		return nil, errs.ErrOutsideDeliveryZone
+	}
+	for i := range zones {
+		zone := &zones[i]
+		// зона со списком индексов, в котором нет индекса адреса, по точке не выбирается
+		if postalCode != "" && len(zone.PostalCodes) > 0 {
+			continue
+		}
+		if zone.Contains(*point) {
+			return zone, nil
+		}
+	}
+	return nil, errs.ErrOutsideDeliveryZone
+}
+
+func (s *deliveryService) Quote(query dto.DeliveryQuoteQuery) (*dto.DeliveryQuoteResponse, error) {
+	var point *models.GeoPoint
+	if query.Lat != nil && query.Lng != nil {
+		point = &models.GeoPoint{Lat: *query.Lat, Lng: *query.Lng}
+	}
+
+	zone, err := s.ResolveZone(query.Address, point)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DeliveryQuoteResponse{
+		ZoneID:           zone.ID,
+		ZoneName:         zone.Name,
+		Fee:              zone.FeeFor(query.Amount),
+		MinOrderValue:    zone.MinOrderValue,
+		FreeDeliveryFrom: zone.FreeDeliveryFrom,
+		BelowMinimum:     query.Amount < zone.MinOrderValue,
+	}, nil
+}
+
+func (s *deliveryService) CreateSlots(zoneID uint, req dto.DeliverySlotsCreate) ([]dto.DeliverySlotResponse, error) {
+	if _, err := s.zones.GetByID(zoneID); err != nil {
+		return nil, err
+	}
+
+	slots := make([]models.DeliverySlot, 0, len(req.Slots))
+	for _, input := range req.Slots {
+		if !input.EndsAt.After(input.StartsAt) {
+			return nil, errs.ErrInvalidDeliverySlot
+		}
+		slots = append(slots, models.DeliverySlot{
+			ZoneID:   zoneID,
+			StartsAt: input.StartsAt,
+			EndsAt:   input.EndsAt,
+			Capacity: input.Capacity,
+			IsActive: true,
+		})
+	}
+	if err := s.slots.Create(slots); err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.DeliverySlotResponse, 0, len(slots))
+	for i := range slots {
+		result = append(result, toDeliverySlotResponse(&slots[i]))
+	}
+	return result, nil
+}
+
+func (s *deliveryService) UpdateSlot(id uint, req dto.DeliverySlotUpdate) (*dto.DeliverySlotResponse, error) {
+	slot, err := s.slots.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Capacity != nil {
+		slot.Capacity = *req.Capacity
+	}
+	if req.IsActive != nil {
+		slot.IsActive = *req.IsActive
+	}
+
+	if err := s.slots.Update(slot); err != nil {
+		return nil, err
+	}
+	resp := toDeliverySlotResponse(slot)
+	return &resp, nil
+}
+
+func (s *deliveryService) AvailableSlots(zoneID uint, query dto.DeliverySlotQuery) ([]dto.DeliverySlotResponse, error) {
+	if _, err := s.zones.GetByID(zoneID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	from := now
+	if query.From != "" {
+		date, err := time.ParseInLocation(time.DateOnly, query.From, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		from = later(date, now)
+	}
+	days := query.Days
+	if days == 0 {
+		days = defaultSlotDays
+	}
+
+	slots, err := s.slots.ListAvailable(zoneID, from, from.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.DeliverySlotResponse, 0, len(slots))
+	for i := range slots {
+		result = append(result, toDeliverySlotResponse(&slots[i]))
+	}
+	return result, nil
+}
+
+func (s *deliveryService) GetSlot(id uint) (*models.DeliverySlot, error) {
+	return s.slots.GetByID(id)
+}
+
+// later - более поздний из двух моментов: начавшиеся слоты уже не предлагаются.
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func toDeliverySlotResponse(slot *models.DeliverySlot) dto.DeliverySlotResponse {
+	var remaining uint
+	if slot.Capacity > slot.Reserved {
+		remaining = slot.Capacity - slot.Reserved
+	}
+	return dto.DeliverySlotResponse{
+		ID:        slot.ID,
+		ZoneID:    slot.ZoneID,
+		StartsAt:  slot.StartsAt,
+		EndsAt:    slot.EndsAt,
+		Capacity:  slot.Capacity,
+		Remaining: remaining,
+		IsActive:  slot.IsActive,
+	}
+}
+
+func validateDeliveryZone(zone *models.DeliveryZone) error {
+	if len(zone.Polygon) > 0 && len(zone.Polygon) < 3 {
+		return errs.ErrInvalidDeliveryZone
+	}
+	if len(zone.PostalCodes) == 0 && len(zone.Polygon) == 0 {
+		return errs.ErrInvalidDeliveryZone
+	}
+	return nil
+}
+
+func normalizePostalCodes(codes []string) []string {
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		result = append(result, strings.TrimSpace(code))
+	}
+	return result
+}
+
+func toGeoPoints(inputs []dto.GeoPointInput) []models.GeoPoint {
+	points := make([]models.GeoPoint, 0, len(inputs))
+	for _, input := range inputs {
+		points = append(points, models.GeoPoint{Lat: *input.Lat, Lng: *input.Lng})
+	}
+	return points
+}
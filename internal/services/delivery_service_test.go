@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// fakeDeliveryZones отдаёт зоны в заданном порядке, как List из БД.
+type fakeDeliveryZones struct {
+	zones []models.DeliveryZone
+}
+
+func (f *fakeDeliveryZones) Create(zone *models.DeliveryZone) error { return nil }
+func (f *fakeDeliveryZones) GetByID(id uint) (*models.DeliveryZone, error) {
+	return nil, errs.ErrDeliveryZoneNotFound
+}
+func (f *fakeDeliveryZones) List(onlyActive bool) ([]models.DeliveryZone, error) {
+	return append([]models.DeliveryZone(nil), f.zones...), nil
+}
+func (f *fakeDeliveryZones) Update(zone *models.DeliveryZone) error { return nil }
+
+func square(lat, lng float64) []models.GeoPoint {
+	return []models.GeoPoint{
+		{Lat: lat, Lng: lng},
+		{Lat: lat, Lng: lng + 1},
+		{Lat: lat + 1, Lng: lng + 1},
+		{Lat: lat + 1, Lng: lng},
+	}
+}
+
+func TestResolveZone(t *testing.T) {
+	zones := &fakeDeliveryZones{zones: []models.DeliveryZone{
+		// дешёвая зона с высоким приоритетом, только по координатам
+		{Model: gorm.Model{ID: 1}, Name: "center", Polygon: square(55, 37), Priority: 0},
+		{Model: gorm.Model{ID: 2}, Name: "suburbs", PostalCodes: []string{"140000"}, Polygon: square(54, 37), Priority: 1},
+		{Model: gorm.Model{ID: 3}, Name: "region", PostalCodes: []string{"150000"}, Priority: 2},
+	}}
+	service := NewDeliveryService(zones, nil)
+
+	center := &models.GeoPoint{Lat: 55.5, Lng: 37.5}
+	suburbs := &models.GeoPoint{Lat: 54.5, Lng: 37.5}
+
+	tests := []struct {
+		name    string
+		address string
+		point   *models.GeoPoint
+		wantID  uint
+		wantErr error
+	}{
+		{"postal code", "г. Москва, 140000, ул. Ленина 1", nil, 2, nil},
+		{"postal code with matching point", "140000, ул. Ленина 1", suburbs, 2, nil},
+		{"point contradicts postal code", "140000, ул. Ленина 1", center, 0, errs.ErrDeliveryPointMismatch},
+		{"zone without polygon trusts postal code", "150000, ул. Мира 2", center, 3, nil},
+		{"point without postal code", "ул. Ленина 1", center, 1, nil},
+		{"unknown postal code ignores zones with postal codes", "199999, ул. Мира 2", suburbs, 0, errs.ErrOutsideDeliveryZone},
+		{"unknown postal code in polygon-only zone", "199999, ул. Мира 2", center, 1, nil},
+		{"no postal code and no point", "ул. Ленина 1", nil, 0, errs.ErrOutsideDeliveryZone},
+		{"point outside every zone", "ул. Ленина 1", &models.GeoPoint{Lat: 10, Lng: 10}, 0, errs.ErrOutsideDeliveryZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, err := service.ResolveZone(tt.address, tt.point)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveZone() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveZone() unexpected error: %v", err)
+			}
+			if zone.ID != tt.wantID {
+				t.Errorf("ResolveZone() zone = %d, want %d", zone.ID, tt.wantID)
+			}
+		})
+	}
+}
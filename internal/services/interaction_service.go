@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// InteractionPolicy определяет, как CreateOrder реагирует на тяжёлые взаимодействия.
+type InteractionPolicy string
+
+const (
+	InteractionPolicyWarn  InteractionPolicy = "warn"
+	InteractionPolicyBlock InteractionPolicy = "block"
+)
+
+type InteractionService interface {
+	Import(req dto.InteractionImportRequest) (*dto.InteractionImportResponse, error)
+	List() ([]dto.InteractionResponse, error)
+	CheckCart(userID uint) (*dto.CartInteractionsResponse, error)
+	CheckMedicines(medicines []*models.Medicine) ([]dto.MedicineInteractionResponse, error)
+}
+
+type interactionService struct {
+	interactions repository.DrugInteractionRepository
+	ingredients  repository.ActiveIngredientRepository
+	medicines    repository.MedicineRepository
+	carts        repository.CartRepository
+}
+
+func NewInteractionService(interactions repository.DrugInteractionRepository,
+	ingredients repository.ActiveIngredientRepository, medicines repository.MedicineRepository,
+	carts repository.CartRepository) InteractionService {
+
+	return &interactionService{interactions: interactions, ingredients: ingredients, medicines: medicines, carts: carts}
+}
+
+func (s *interactionService) Import(req dto.InteractionImportRequest) (*dto.InteractionImportResponse, error) {
+	inns := make([]string, 0, len(req.Interactions)*2)
+	for _, item := range req.Interactions {
+		innA, innB := normalizeINN(item.INNA), normalizeINN(item.INNB)
+		if innA == "" || innB == "" || innA == innB || !item.Severity.IsValid() {
+			return nil, errs.ErrInvalidInteraction
+		}
+		inns = append(inns, innA, innB)
+	}
+
+	byINN, err := s.ingredients.EnsureByINN(uniqueStrings(inns))
+	if err != nil {
+		return nil, err
+	}
+
+	// одна пара может встретиться в файле несколько раз - побеждает последняя запись
+	type pair struct{ a, b uint }
+	byPair := make(map[pair]models.DrugInteraction, len(req.Interactions))
+	order := make([]pair, 0, len(req.Interactions))
+	for _, item := range req.Interactions {
+		a, b := byINN[normalizeINN(item.INNA)].ID, byINN[normalizeINN(item.INNB)].ID
+		if a > b {
+			a, b = b, a
+		}
+		key := pair{a, b}
+		if _, ok := byPair[key]; !ok {
+			order = append(order, key)
+		}
+		byPair[key] = models.DrugInteraction{
+			IngredientAID: a,
+			IngredientBID: b,
+			Severity:      item.Severity,
+			Description:   item.Description,
+		}
+	}
+
+	interactions := make([]models.DrugInteraction, 0, len(order))
+	for _, key := range order {
+		interactions = append(interactions, byPair[key])
+	}
+	if err := s.interactions.Upsert(interactions); err != nil {
+		return nil, err
+	}
+
+	return &dto.InteractionImportResponse{Imported: len(interactions)}, nil
+}
+
+func (s *interactionService) List() ([]dto.InteractionResponse, error) {
+	interactions, err := s.interactions.List()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.InteractionResponse, 0, len(interactions))
+	for _, interaction := range interactions {
+		resp = append(resp, dto.InteractionResponse{
+			ID:          interaction.ID,
+			INNA:        interaction.IngredientA.INN,
+			INNB:        interaction.IngredientB.INN,
+			Severity:    interaction.Severity,
+			Description: interaction.Description,
+		})
+	}
+	return resp, nil
+}
+
+func (s *interactionService) CheckCart(userID uint) (*dto.CartInteractionsResponse, error) {
+	if userID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	resp := &dto.CartInteractionsResponse{Interactions: []dto.MedicineInteractionResponse{}}
+
+	cart, err := s.carts.GetCartWithItems(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	medicines := make([]*models.Medicine, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.Medicine != nil {
+			medicines = append(medicines, item.Medicine)
+		}
+	}
+
+	found, err := s.CheckMedicines(medicines)
+	if err != nil {
+		return nil, err
+	}
+	resp.Interactions = found
+	resp.HasSevere = hasSevereInteraction(found)
+	return resp, nil
+}
+
+// CheckMedicines находит взаимодействия между разными лекарствами из списка.
+// Результат отсортирован от самых тяжёлых.
+func (s *interactionService) CheckMedicines(medicines []*models.Medicine) ([]dto.MedicineInteractionResponse, error) {
+	result := []dto.MedicineInteractionResponse{}
+	if len(medicines) < 2 {
+		return result, nil
+	}
+
+	byID := make(map[uint]*models.Medicine, len(medicines))
+	medicineIDs := make([]uint, 0, len(medicines))
+	for _, medicine := range medicines {
+		if _, ok := byID[medicine.ID]; !ok {
+			byID[medicine.ID] = medicine
+			medicineIDs = append(medicineIDs, medicine.ID)
+		}
+	}
+
+	composition, err := s.medicines.IngredientsOf(medicineIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	medicinesByIngredient := make(map[uint][]uint)
+	ingredientIDs := make([]uint, 0, len(composition))
+	for _, mi := range composition {
+		if _, ok := medicinesByIngredient[mi.ActiveIngredientID]; !ok {
+			ingredientIDs = append(ingredientIDs, mi.ActiveIngredientID)
+		}
+		medicinesByIngredient[mi.ActiveIngredientID] = append(medicinesByIngredient[mi.ActiveIngredientID], mi.MedicineID)
+	}
+
+	interactions, err := s.interactions.ListAmong(ingredientIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ medA, medB, interactionID uint }
+	seen := make(map[key]bool)
+	for _, interaction := range interactions {
+		for _, medA := range medicinesByIngredient[interaction.IngredientAID] {
+			for _, medB := range medicinesByIngredient[interaction.IngredientBID] {
+				if medA == medB {
+					continue
+				}
+				k := key{min(medA, medB), max(medA, medB), interaction.ID}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+
+				result = append(result, dto.MedicineInteractionResponse{
+					MedicineA:   dto.InteractionMedicineResponse{ID: medA, Name: byID[medA].Name},
+					MedicineB:   dto.InteractionMedicineResponse{ID: medB, Name: byID[medB].Name},
+					INNA:        interaction.IngredientA.INN,
+					INNB:        interaction.IngredientB.INN,
+					Severity:    interaction.Severity,
+					Description: interaction.Description,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return severityRank(result[i].Severity) > severityRank(result[j].Severity)
+	})
+	return result, nil
+}
+
+func severityRank(severity models.InteractionSeverity) int {
+	switch severity {
+	case models.InteractionSeveritySevere:
+		return 3
+	case models.InteractionSeverityModerate:
+		return 2
+	case models.InteractionSeverityMinor:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func hasSevereInteraction(interactions []dto.MedicineInteractionResponse) bool {
+	for _, interaction := range interactions {
+		if interaction.Severity == models.InteractionSeveritySevere {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/repository"
+)
+
+type InventoryService interface {
+	LowStockReport() ([]dto.LowStockItemResponse, error)
+	// CheckLowStock оповещает о лекарствах, остаток которых опустился до точки
+	// заказа, - по одному разу, пока остаток снова не поднимется. Возвращает
+	// число отправленных оповещений.
+	CheckLowStock(ctx context.Context) (int, error)
+}
+
+type inventoryService struct {
+	inventory repository.InventoryRepository
+	notifier  Notifier
+	lookback  time.Duration
+	coverage  time.Duration
+}
+
+func NewInventoryService(inventory repository.InventoryRepository, notifier Notifier,
+	lookback, coverage time.Duration) InventoryService {
+
+	return &inventoryService{inventory: inventory, notifier: notifier, lookback: lookback, coverage: coverage}
+}
+
+func (s *inventoryService) LowStockReport() ([]dto.LowStockItemResponse, error) {
+	items, err := s.inventory.LowStock(time.Now().Add(-s.lookback))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.LowStockItemResponse, 0, len(items))
+	for _, item := range items {
+		result = append(result, s.toLowStockResponse(item))
+	}
+	return result, nil
+}
+
+func (s *inventoryService) CheckLowStock(ctx context.Context) (int, error) {
+	if _, err := s.inventory.ResetRecoveredAlerts(); err != nil {
+		return 0, err
+	}
+
+	items, err := s.inventory.PendingLowStockAlerts(time.Now().Add(-s.lookback))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		report := s.toLowStockResponse(item)
+		notification := Notification{
+			Kind:    "low_stock",
+			Subject: fmt.Sprintf("Заканчивается: %s", item.Name),
+			Text: fmt.Sprintf("Остаток %d при точке заказа %d, рекомендуем заказать %d шт.",
+				item.StockQuantity, item.ReorderPoint, report.SuggestedQuantity),
+			Data: map[string]any{
+				"medicine_id":        item.MedicineID,
+				"stock_quantity":     item.StockQuantity,
+				"reorder_point":      item.ReorderPoint,
+				"suggested_quantity": report.SuggestedQuantity,
+			},
+		}
+		// отметку ставим только после успешной отправки, чтобы не потерять оповещение
+		if err := s.notifier.Notify(ctx, notification); err != nil {
+			return sent, err
+		}
+		if err := s.inventory.MarkLowStockAlerted(item.MedicineID, time.Now()); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *inventoryService) toLowStockResponse(item repository.LowStockItem) dto.LowStockItemResponse {
+	lookbackDays := max(int(s.lookback.Hours()/24), 1)
+	return dto.LowStockItemResponse{
+		MedicineID:        item.MedicineID,
+		Name:              item.Name,
+		StockQuantity:     item.StockQuantity,
+		ReorderPoint:      item.ReorderPoint,
+		SoldQuantity:      item.SoldQuantity,
+		LookbackDays:      lookbackDays,
+		SuggestedQuantity: suggestReorderQuantity(item, lookbackDays, s.coverage),
+		AlertedAt:         item.LowStockAlertedAt,
+	}
+}
+
+// suggestReorderQuantity - сколько заказать, чтобы после пополнения остаток
+// покрывал продажи за coverage при среднем дневном спросе за период и не
+// опускался ниже точки заказа.
+func suggestReorderQuantity(item repository.LowStockItem, lookbackDays int, coverage time.Duration) uint {
+	dailyDemand := float64(item.SoldQuantity) / float64(lookbackDays)
+	target := uint(math.Ceil(dailyDemand*coverage.Hours()/24)) + item.ReorderPoint
+	if target <= item.StockQuantity {
+		return 0
+	}
+	return target - item.StockQuantity
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"team-pharmacy/internal/repository"
+)
+
+func TestSuggestReorderQuantity(t *testing.T) {
+	const week = 7 * 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		item     repository.LowStockItem
+		lookback int
+		coverage time.Duration
+		want     uint
+	}{
+		{
+			name:     "covers demand and restores the reorder point",
+			item:     repository.LowStockItem{StockQuantity: 5, ReorderPoint: 10, SoldQuantity: 30},
+			lookback: 30,
+			coverage: 2 * week,
+			want:     19, // 1 в день * 14 дней + 10 - 5
+		},
+		{
+			name:     "fractional demand is rounded up",
+			item:     repository.LowStockItem{StockQuantity: 0, ReorderPoint: 0, SoldQuantity: 10},
+			lookback: 30,
+			coverage: week,
+			want:     3, // 10/30 * 7 = 2.33
+		},
+		{
+			name:     "no sales only restores the reorder point",
+			item:     repository.LowStockItem{StockQuantity: 2, ReorderPoint: 10},
+			lookback: 30,
+			coverage: 2 * week,
+			want:     8,
+		},
+		{
+			name:     "stock already above target",
+			item:     repository.LowStockItem{StockQuantity: 50, ReorderPoint: 10, SoldQuantity: 30},
+			lookback: 30,
+			coverage: 2 * week,
+			want:     0,
+		},
+		{
+			name:     "stock exactly at target",
+			item:     repository.LowStockItem{StockQuantity: 24, ReorderPoint: 10, SoldQuantity: 30},
+			lookback: 30,
+			coverage: 2 * week,
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestReorderQuantity(tt.item, tt.lookback, tt.coverage); got != tt.want {
+				t.Errorf("suggestReorderQuantity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
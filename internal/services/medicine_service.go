@@ -2,28 +2,43 @@ package services
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
 	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 type MedicineService interface {
 	Create(req dto.MedicineCreate) (*models.Medicine, error)
-	GetAll() ([]models.Medicine, error)
+	List(query dto.MedicineListQuery) (*dto.MedicineListResponse, error)
+	Search(query dto.MedicineSearchQuery) (*dto.MedicineSearchResponse, error)
 	GetByID(id uint) (*models.Medicine, error)
+	Analogs(id uint) ([]models.Medicine, error)
+	ListVariants(medicineID uint) ([]models.MedicineVariant, error)
+	CreateVariant(medicineID uint, req dto.MedicineVariantInput) (*models.MedicineVariant, error)
+	UpdateVariant(medicineID, variantID uint, req dto.MedicineVariantUpdate) (*models.MedicineVariant, error)
+	DeleteVariant(medicineID, variantID uint) error
 	Update(req dto.MedicineUpdate, id uint) error
 	Delete(id uint) error
 }
 
 type medicineService struct {
-	MedicineRepo  repository.MedicineRepository
-	CategoryRP    repository.CategoryRepository
-	SubCategoryRP repository.SubcategoryRepository
+	MedicineRepo   repository.MedicineRepository
+	CategoryRP     repository.CategoryRepository
+	SubCategoryRP  repository.SubcategoryRepository
+	IngredientRepo repository.ActiveIngredientRepository
+	VariantRepo    repository.MedicineVariantRepository
 }
 
-func NewMedicineService(medicineRepo repository.MedicineRepository, categoryRepo repository.CategoryRepository, subcategoryRepo repository.SubcategoryRepository) MedicineService {
-	return &medicineService{MedicineRepo: medicineRepo, CategoryRP: categoryRepo, SubCategoryRP: subcategoryRepo}
+func NewMedicineService(medicineRepo repository.MedicineRepository, categoryRepo repository.CategoryRepository,
+	subcategoryRepo repository.SubcategoryRepository, ingredientRepo repository.ActiveIngredientRepository,
+	variantRepo repository.MedicineVariantRepository) MedicineService {
+	return &medicineService{MedicineRepo: medicineRepo, CategoryRP: categoryRepo, SubCategoryRP: subcategoryRepo,
+		IngredientRepo: ingredientRepo, VariantRepo: variantRepo}
 }
 
 func (m *medicineService) Create(req dto.MedicineCreate) (*models.Medicine, error) {
@@ -41,30 +56,205 @@ func (m *medicineService) Create(req dto.MedicineCreate) (*models.Medicine, erro
 	if name == "" {
 		return nil, errors.New("write the Name")
 	}
-	// Cheking Price
-	if req.Price <= 0 {
-		return nil, errors.New("isnt Correct Price")
+	// Cheking Variants: без списка вариантов создаётся один из price, остаток приходит партиями
+	variants, err := buildVariants(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredients, err := m.buildIngredients(req.Ingredients)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create
 	medicine := &models.Medicine{
 		Name:                 name,
 		Description:          req.Description,
-		Price:                int64(req.Price),
-		StockQuantity:        req.StockQuantity,
+		Price:                variants[0].Price,
 		CategoryID:           req.CategoryID,
 		SubcategoryID:        req.SubcategoryID,
 		Manufacturer:         req.Manufacturer,
 		PrescriptionRequired: req.PrescriptionRequired,
+		DosageForm:           req.DosageForm,
+		ReorderPoint:         req.ReorderPoint,
+		Ingredients:          ingredients,
+		Variants:             variants,
 	}
 	if err := m.MedicineRepo.Create(medicine); err != nil {
 		return nil, err
 	}
-	return medicine, nil
+	return m.MedicineRepo.GetByID(medicine.ID)
 }
-func (m *medicineService) GetAll() ([]models.Medicine, error) {
-	return m.MedicineRepo.GetAll()
+
+func buildVariants(req dto.MedicineCreate) ([]models.MedicineVariant, error) {
+	if len(req.Variants) == 0 {
+		if req.Price <= 0 {
+			return nil, errors.New("isnt Correct Price")
+		}
+		return []models.MedicineVariant{{
+			Name:  models.DefaultVariantName,
+			Price: req.Price,
+		}}, nil
+	}
+
+	variants := make([]models.MedicineVariant, 0, len(req.Variants))
+	for _, input := range req.Variants {
+		variant, err := newVariant(input)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, *variant)
+	}
+	return variants, nil
 }
+
+func newVariant(input dto.MedicineVariantInput) (*models.MedicineVariant, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("variant name isnt correct")
+	}
+	if input.Price == 0 {
+		return nil, errors.New("isnt Correct Price")
+	}
+	return &models.MedicineVariant{
+		SKU:     strings.TrimSpace(input.SKU),
+		Name:    name,
+		Barcode: trimmedOrNil(input.Barcode),
+		Price:   input.Price,
+	}, nil
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+const (
+	defaultMedicinePageSize = 20
+	maxMedicinePageSize     = 100
+)
+
+func (m *medicineService) List(query dto.MedicineListQuery) (*dto.MedicineListResponse, error) {
+	filter := repository.MedicineFilter{
+		Query:                strings.TrimSpace(query.Query),
+		CategoryID:           query.CategoryID,
+		SubcategoryID:        query.SubcategoryID,
+		Manufacturer:         strings.TrimSpace(query.Manufacturer),
+		PriceMin:             query.PriceMin,
+		PriceMax:             query.PriceMax,
+		InStock:              query.InStock,
+		PrescriptionRequired: query.PrescriptionRequired,
+		MinRating:            query.MinRating,
+		WarehouseID:          query.WarehouseID,
+		Sort:                 repository.MedicineSort(query.Sort),
+		Offset:               query.Offset,
+		Limit:                query.Limit,
+	}
+	if filter.Sort == "" {
+		filter.Sort = repository.MedicineSortNewest
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultMedicinePageSize
+	}
+	if filter.Limit > maxMedicinePageSize {
+		filter.Limit = maxMedicinePageSize
+	}
+	if filter.PriceMin != nil && filter.PriceMax != nil && *filter.PriceMin > *filter.PriceMax {
+		return nil, errs.ErrInvalidCatalogQuery
+	}
+	if query.Cursor != "" {
+		afterID, err := strconv.ParseUint(query.Cursor, 10, 64)
+		if err != nil || afterID == 0 {
+			return nil, errs.ErrInvalidCatalogQuery
+		}
+		filter.AfterID = uint(afterID)
+		filter.Offset = 0
+	}
+
+	medicines, total, err := m.MedicineRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := m.MedicineRepo.Facets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MedicineListResponse{
+		Items:  medicines,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+		Facets: dto.MedicineFacetsResponse{
+			Categories:    make([]dto.CategoryFacetResponse, 0, len(facets.Categories)),
+			Manufacturers: make([]dto.ManufacturerFacetResponse, 0, len(facets.Manufacturers)),
+		},
+	}
+	if resp.Items == nil {
+		resp.Items = []models.Medicine{}
+	}
+	// полная страница - возможно, есть следующая
+	if len(medicines) == filter.Limit {
+		resp.NextCursor = strconv.FormatUint(uint64(medicines[len(medicines)-1].ID), 10)
+	}
+	for _, f := range facets.Categories {
+		resp.Facets.Categories = append(resp.Facets.Categories, dto.CategoryFacetResponse{
+			CategoryID: f.CategoryID,
+			Name:       f.Name,
+			Count:      f.Count,
+		})
+	}
+	for _, f := range facets.Manufacturers {
+		resp.Facets.Manufacturers = append(resp.Facets.Manufacturers, dto.ManufacturerFacetResponse{
+			Manufacturer: f.Manufacturer,
+			Count:        f.Count,
+		})
+	}
+	return resp, nil
+}
+func (m *medicineService) Search(query dto.MedicineSearchQuery) (*dto.MedicineSearchResponse, error) {
+	text := strings.TrimSpace(query.Query)
+	if text == "" {
+		return nil, errs.ErrInvalidCatalogQuery
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultMedicinePageSize
+	}
+	if limit > maxMedicinePageSize {
+		limit = maxMedicinePageSize
+	}
+
+	result, err := m.MedicineRepo.Search(text, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MedicineSearchResponse{
+		Mode:   string(result.Mode),
+		Total:  result.Total,
+		Limit:  limit,
+		Offset: query.Offset,
+		Items:  make([]dto.MedicineSearchHit, 0, len(result.Hits)),
+	}
+	for _, hit := range result.Hits {
+		resp.Items = append(resp.Items, dto.MedicineSearchHit{
+			Medicine: hit.Medicine,
+			Rank:     hit.Rank,
+			Name:     hit.NameHighlight,
+			Snippet:  hit.Snippet,
+		})
+	}
+	return resp, nil
+}
+
 func (m *medicineService) GetByID(id uint) (*models.Medicine, error) {
 	if id == 0 {
 		return nil, errors.New("id can,t be zero")
@@ -101,15 +291,17 @@ func (m *medicineService) Update(req dto.MedicineUpdate, id uint) error {
 		medicine.Manufacturer = manufacturer
 	}
 
+	// цена хранится в вариантах; напрямую её можно менять, только если фасовка одна
+	var variant *models.MedicineVariant
 	if req.Price != nil {
+		if len(medicine.Variants) != 1 {
+			return errs.ErrVariantRequired
+		}
 		if *req.Price <= 0 {
 			return errors.New("isnt Correct Price")
 		}
-		medicine.Price = int64(*req.Price)
-	}
-
-	if req.StockQuantity != nil {
-		medicine.StockQuantity = *req.StockQuantity
+		variant = &medicine.Variants[0]
+		variant.Price = *req.Price
 	}
 
 	if req.CategoryID != nil {
@@ -143,10 +335,186 @@ func (m *medicineService) Update(req dto.MedicineUpdate, id uint) error {
 		medicine.PrescriptionRequired = *req.PrescriptionRequired
 	}
 
-	if err := m.MedicineRepo.Update(medicine); err != nil {
+	if req.DosageForm != nil {
+		medicine.DosageForm = *req.DosageForm
+	}
+
+	if req.ReorderPoint != nil {
+		medicine.ReorderPoint = *req.ReorderPoint
+	}
+
+	// nil - состав не меняется, репозиторий перезаписывает его только если список задан
+	medicine.Ingredients = nil
+	if req.Ingredients != nil {
+		ingredients, err := m.buildIngredients(req.Ingredients)
+		if err != nil {
+			return err
+		}
+		medicine.Ingredients = ingredients
+	}
+
+	if variant != nil {
+		return m.MedicineRepo.Update(medicine, variant)
+	}
+	return m.MedicineRepo.Update(medicine)
+}
+
+func (m *medicineService) ListVariants(medicineID uint) ([]models.MedicineVariant, error) {
+	if _, err := m.getMedicine(medicineID); err != nil {
+		return nil, err
+	}
+	return m.VariantRepo.ListByMedicine(medicineID)
+}
+
+func (m *medicineService) CreateVariant(medicineID uint, req dto.MedicineVariantInput) (*models.MedicineVariant, error) {
+	if _, err := m.getMedicine(medicineID); err != nil {
+		return nil, err
+	}
+
+	variant, err := newVariant(req)
+	if err != nil {
+		return nil, err
+	}
+	variant.MedicineID = medicineID
+	if err := m.VariantRepo.Create(variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (m *medicineService) UpdateVariant(medicineID, variantID uint, req dto.MedicineVariantUpdate) (*models.MedicineVariant, error) {
+	variant, err := m.getVariant(medicineID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SKU != nil {
+		sku := strings.TrimSpace(*req.SKU)
+		if sku == "" {
+			return nil, errors.New("sku isnt correct")
+		}
+		variant.SKU = sku
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("variant name isnt correct")
+		}
+		variant.Name = name
+	}
+	if req.Barcode != nil {
+		variant.Barcode = trimmedOrNil(req.Barcode)
+	}
+	if req.Price != nil {
+		if *req.Price == 0 {
+			return nil, errors.New("isnt Correct Price")
+		}
+		variant.Price = *req.Price
+	}
+	if err := m.VariantRepo.Update(variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (m *medicineService) DeleteVariant(medicineID, variantID uint) error {
+	variant, err := m.getVariant(medicineID, variantID)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	variants, err := m.VariantRepo.ListByMedicine(medicineID)
+	if err != nil {
+		return err
+	}
+	if len(variants) <= 1 {
+		return errs.ErrLastVariant
+	}
+	return m.VariantRepo.Delete(variant)
+}
+
+func (m *medicineService) getMedicine(id uint) (*models.Medicine, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	medicine, err := m.MedicineRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrMedicineNotFound
+		}
+		return nil, err
+	}
+	return medicine, nil
+}
+
+func (m *medicineService) getVariant(medicineID, variantID uint) (*models.MedicineVariant, error) {
+	if medicineID == 0 || variantID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	variant, err := m.VariantRepo.GetByID(variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.MedicineID != medicineID {
+		return nil, errs.ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+func (m *medicineService) Analogs(id uint) ([]models.Medicine, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	if _, err := m.MedicineRepo.GetByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrMedicineNotFound
+		}
+		return nil, err
+	}
+	return m.MedicineRepo.Analogs(id)
+}
+
+// buildIngredients нормализует МНН и единицы дозировки, чтобы аналоги
+// сравнивались по точному совпадению, и подставляет id действующих веществ.
+func (m *medicineService) buildIngredients(inputs []dto.MedicineIngredientInput) ([]models.MedicineIngredient, error) {
+	if inputs == nil {
+		return nil, nil
+	}
+
+	inns := make([]string, 0, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		inn := normalizeINN(input.INN)
+		if inn == "" {
+			return nil, errors.New("inn cant be empty")
+		}
+		if seen[inn] {
+			return nil, errors.New("duplicate inn in ingredients")
+		}
+		seen[inn] = true
+		inns = append(inns, inn)
+	}
+
+	byINN, err := m.IngredientRepo.EnsureByINN(inns)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredients := make([]models.MedicineIngredient, 0, len(inputs))
+	for i, input := range inputs {
+		ingredient := byINN[inns[i]]
+		ingredients = append(ingredients, models.MedicineIngredient{
+			ActiveIngredientID: ingredient.ID,
+			ActiveIngredient:   &ingredient,
+			Strength:           input.Strength,
+			StrengthUnit:       strings.ToLower(strings.TrimSpace(input.StrengthUnit)),
+		})
+	}
+	return ingredients, nil
+}
+
+func normalizeINN(inn string) string {
+	return strings.ToLower(strings.Join(strings.Fields(inn), " "))
 }
 
 func (m *medicineService) Delete(id uint) error {
@@ -1,11 +1,17 @@
 package services
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
 	"team-pharmacy/internal/dto"
 	"team-pharmacy/internal/errs"
 	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/repository"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,20 +20,44 @@ type OrderService interface {
 	CreateOrder(userID uint, req *dto.OrderCreateRequest) (*dto.OrderResponse, error)
 	GetByID(orderID uint) (*dto.OrderResponse, error)
 	GetListOrders(userID uint) ([]dto.OrderShortResponse, error)
-	UpdateOrder(orderID uint, req *dto.OrderStatusRequest) error
+	UpdateOrder(orderID, actorID uint, actorRole models.Role, req *dto.OrderStatusRequest) error
+	CancelOrder(orderID, actorID uint, req *dto.OrderCancelRequest) (*dto.OrderResponse, error)
+	ExpireUnpaidOrders(paymentWindow time.Duration) (int, error)
+	// RetryFailedRefunds повторно проводит возвраты, отклонённые провайдером,
+	// и возвращает число успешных.
+	RetryFailedRefunds() (int, error)
+	GetHistory(orderID uint) ([]dto.OrderEventResponse, error)
+	// CompletePickup выдаёт заказ в пункте самовывоза по коду получения.
+	CompletePickup(orderID, actorID uint, req *dto.OrderPickupRequest) (*dto.OrderResponse, error)
 }
 
 type orderService struct {
-	orderRepo    repository.OrderRepository
-	userRepo     repository.UserRepository
-	cartRepo     repository.CartRepository
-	medicineRepo repository.MedicineRepository
+	orderRepo        repository.OrderRepository
+	userRepo         repository.UserRepository
+	cartRepo         repository.CartRepository
+	medicineRepo     repository.MedicineRepository
+	prescriptionRepo repository.PrescriptionRepository
+	promocodes       PromocodeService
+	provider         PaymentProvider
+	interactions     InteractionService
+	policy           InteractionPolicy
+	warehouses       WarehouseService
+	pickupPoints     repository.PickupPointRepository
+	deliveries       DeliveryService
+	logger           *slog.Logger
 }
 
 func NewOrderService(orderRepo repository.OrderRepository, userRepo repository.UserRepository,
-	cartRepo repository.CartRepository, medicineRepo repository.MedicineRepository) OrderService {
-
-	return &orderService{orderRepo: orderRepo, userRepo: userRepo, cartRepo: cartRepo, medicineRepo: medicineRepo}
+	cartRepo repository.CartRepository, medicineRepo repository.MedicineRepository,
+	prescriptionRepo repository.PrescriptionRepository, promocodes PromocodeService,
+	provider PaymentProvider, interactions InteractionService, policy InteractionPolicy,
+	warehouses WarehouseService, pickupPoints repository.PickupPointRepository,
+	deliveries DeliveryService, logger *slog.Logger) OrderService {
+
+	return &orderService{orderRepo: orderRepo, userRepo: userRepo, cartRepo: cartRepo, medicineRepo: medicineRepo,
+		prescriptionRepo: prescriptionRepo, promocodes: promocodes, provider: provider, interactions: interactions,
+		policy: policy, warehouses: warehouses, pickupPoints: pickupPoints, deliveries: deliveries,
+		logger: logger.With("layer", "service", "entity", "order")}
 }
 
 func (s *orderService) CreateOrder(userID uint, req *dto.OrderCreateRequest) (*dto.OrderResponse, error) {
@@ -55,17 +85,39 @@ func (s *orderService) CreateOrder(userID uint, req *dto.OrderCreateRequest) (*d
 		return nil, errs.ErrCartIsEmpty
 	}
 
+	cartMedicines := make([]*models.Medicine, 0, len(cart.Items))
+	for _, cartItem := range cart.Items {
+		cartMedicines = append(cartMedicines, cartItem.Medicine)
+	}
+	if err := ensurePrescriptions(s.prescriptionRepo, userID, cartMedicines); err != nil {
+		return nil, err
+	}
+
+	interactions, err := s.interactions.CheckMedicines(cartMedicines)
+	if err != nil {
+		return nil, err
+	}
+	if s.policy == InteractionPolicyBlock && hasSevereInteraction(interactions) {
+		return nil, errs.ErrSevereInteraction
+	}
+
 	var (
 		orderItems []models.OrderItem
 		totalPrice int64
 	)
 
 	for _, cartItem := range cart.Items {
+		if cartItem.Variant == nil {
+			return nil, errs.ErrVariantNotFound
+		}
 		lineTotal := int64(cartItem.Quantity) * cartItem.PricePerUnit
 
 		orderItems = append(orderItems, models.OrderItem{
 			MedicineID:   cartItem.MedicineID,
+			VariantID:    cartItem.VariantID,
 			MedicineName: cartItem.Medicine.Name,
+			VariantName:  cartItem.Variant.Name,
+			SKU:          cartItem.Variant.SKU,
 			Quantity:     cartItem.Quantity,
 			PricePerUnit: cartItem.PricePerUnit,
 			LineTotal:    lineTotal,
@@ -73,44 +125,84 @@ func (s *orderService) CreateOrder(userID uint, req *dto.OrderCreateRequest) (*d
 		totalPrice += lineTotal
 	}
 
+	fulfilment, err := s.resolveFulfilment(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var pickupWarehouseID *uint
+	if fulfilment.point != nil {
+		pickupWarehouseID = &fulfilment.point.WarehouseID
+	}
+	warehouse, err := s.warehouses.PickFulfilment(fulfilment.address, pickupWarehouseID, orderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		promocodeID *uint
+		discount    int64
+	)
+
+	if strings.TrimSpace(req.Promocode) != "" {
+		promocode, promoDiscount, err := s.promocodes.Apply(userID, req.Promocode, cart.Items)
+		if err != nil {
+			return nil, err
+		}
+		promocodeID = &promocode.ID
+		discount = promoDiscount
+	}
+
+	// минимальная сумма и бесплатная доставка считаются от суммы товаров со скидкой
+	var (
+		deliveryFee    int64
+		deliveryZoneID *uint
+	)
+	if zone := fulfilment.zone; zone != nil {
+		if totalPrice-discount < zone.MinOrderValue {
+			return nil, errs.ErrBelowMinimumOrder
+		}
+		deliveryFee = zone.FeeFor(totalPrice - discount)
+		deliveryZoneID = &zone.ID
+	}
+	var deliverySlotID *uint
+	if fulfilment.slot != nil {
+		deliverySlotID = &fulfilment.slot.ID
+	}
+
 	order := models.Order{
 		UserID:          userID,
 		Status:          models.OrderStatusPendingPayment,
 		TotalPrice:      totalPrice,
-		DiscountTotal:   0,
-		FinalPrice:      totalPrice,
-		DeliveryAddress: req.DeliveryAddress,
+		DiscountTotal:   discount,
+		FinalPrice:      totalPrice - discount + deliveryFee,
+		PromocodeID:     promocodeID,
+		DeliveryFee:     deliveryFee,
+		DeliveryZoneID:  deliveryZoneID,
+		DeliverySlotID:  deliverySlotID,
+		FulfilmentMode:  fulfilment.mode,
+		DeliveryAddress: fulfilment.address,
 		Comment:         req.Comment,
+		WarehouseID:     &warehouse.ID,
 		Items:           orderItems,
 	}
+	if fulfilment.point != nil {
+		order.PickupPointID = &fulfilment.point.ID
+		if order.PickupCode, err = newPickupCode(); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.orderRepo.CreateOrderWithClearCart(&order, cart.ID); err != nil {
 		return nil, err
 	}
+	order.DeliverySlot = fulfilment.slot
 
-	itemsResp := make([]dto.OrderItemResponse, 0, len(order.Items))
-
-	for _, item := range order.Items {
-		itemsResp = append(itemsResp, dto.OrderItemResponse{
-			MedicineID:   item.MedicineID,
-			MedicineName: item.MedicineName,
-			Quantity:     item.Quantity,
-			PricePerUnit: item.PricePerUnit,
-			LineTotal:    item.LineTotal,
-		})
+	resp := orderToResponse(&order)
+	if len(interactions) > 0 {
+		resp.InteractionWarnings = interactions
 	}
-
-	return &dto.OrderResponse{
-		UserID:          order.UserID,
-		Status:          string(order.Status),
-		TotalPrice:      order.TotalPrice,
-		DiscountTotal:   order.DiscountTotal,
-		FinalPrice:      order.FinalPrice,
-		DeliveryAddress: order.DeliveryAddress,
-		Comment:         order.Comment,
-		Items:           itemsResp,
-		CreatedAt:       order.CreatedAt,
-	}, nil
+	return resp, nil
 }
 
 func (s *orderService) GetByID(orderID uint) (*dto.OrderResponse, error) {
@@ -123,30 +215,7 @@ func (s *orderService) GetByID(orderID uint) (*dto.OrderResponse, error) {
 		return nil, err
 	}
 
-	itemsResp := make([]dto.OrderItemResponse, 0, len(order.Items))
-
-	for _, item := range order.Items {
-		itemsResp = append(itemsResp, dto.OrderItemResponse{
-			MedicineID:   item.MedicineID,
-			MedicineName: item.MedicineName,
-			Quantity:     item.Quantity,
-			PricePerUnit: item.PricePerUnit,
-			LineTotal:    item.LineTotal,
-		})
-	}
-
-	return &dto.OrderResponse{
-		UserID:          order.UserID,
-		Status:          string(order.Status),
-		TotalPrice:      order.TotalPrice,
-		DiscountTotal:   order.DiscountTotal,
-		FinalPrice:      order.FinalPrice,
-		DeliveryAddress: order.DeliveryAddress,
-		Comment:         order.Comment,
-		Items:           itemsResp,
-		CreatedAt:       order.CreatedAt,
-		// Payments:        order.Payments,
-	}, nil
+	return orderToResponse(order), nil
 
 }
 
@@ -180,7 +249,7 @@ func (s *orderService) GetListOrders(userID uint) ([]dto.OrderShortResponse, err
 
 }
 
-func (s *orderService) UpdateOrder(orderID uint, req *dto.OrderStatusRequest) error {
+func (s *orderService) UpdateOrder(orderID, actorID uint, actorRole models.Role, req *dto.OrderStatusRequest) error {
 	if orderID == 0 {
 		return errs.ErrInvalidID
 	}
@@ -194,9 +263,322 @@ func (s *orderService) UpdateOrder(orderID uint, req *dto.OrderStatusRequest) er
 		return err
 	}
 	newStatus := *req.Status
-	if !models.CanChangeOrderStatus(order.Status, newStatus) {
+	// отмена возвращает остатки и деньги, поэтому идёт только через CancelOrder;
+	// отгрузка и вручение - только через отправление или код получения
+	switch newStatus {
+	case models.OrderStatusCanceled, models.OrderStatusShipped, models.OrderStatusCompleted:
+		return errs.ErrInvalidStatus
+	}
+	if !order.CanChangeStatus(newStatus) {
 		return errs.ErrInvalidStatus
 	}
+	if !models.CanRoleChangeOrderStatus(actorRole, order.Status, newStatus) {
+		return errs.ErrForbidden
+	}
+
+	return s.orderRepo.UpdateOrder(orderID, &newStatus, &actorID, strings.TrimSpace(req.Reason))
+}
+
+func (s *orderService) GetHistory(orderID uint) ([]dto.OrderEventResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	if _, err := s.orderRepo.GetByID(orderID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.orderRepo.ListEvents(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return orderEventsToResponse(events), nil
+}
+
+func (s *orderService) CancelOrder(orderID, actorID uint, req *dto.OrderCancelRequest) (*dto.OrderResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	order, err := s.orderRepo.CancelOrder(orderID, &actorID, strings.TrimSpace(req.Reason), "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.processRefunds(order)
+
+	return orderToResponse(order), nil
+}
+
+func (s *orderService) CompletePickup(orderID, actorID uint, req *dto.OrderPickupRequest) (*dto.OrderResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	if err := s.orderRepo.CompletePickup(orderID, strings.TrimSpace(req.Code), &actorID); err != nil {
+		return nil, err
+	}
+	return s.GetByID(orderID)
+}
+
+// orderFulfilment - способ получения заказа: адрес и зона доставки или пункт самовывоза.
+type orderFulfilment struct {
+	mode    models.FulfilmentMode
+	address string
+	zone    *models.DeliveryZone
+	slot    *models.DeliverySlot
+	point   *models.PickupPoint
+}
+
+func (s *orderService) resolveFulfilment(req *dto.OrderCreateRequest) (*orderFulfilment, error) {
+	if req.FulfilmentMode != models.FulfilmentPickup {
+		address := strings.TrimSpace(req.DeliveryAddress)
+		if address == "" {
+			return nil, errs.ErrDeliveryAddressRequired
+		}
+		var location *models.GeoPoint
+		if req.DeliveryLat != nil && req.DeliveryLng != nil {
+			location = &models.GeoPoint{Lat: *req.DeliveryLat, Lng: *req.DeliveryLng}
+		}
+		zone, err := s.deliveries.ResolveZone(address, location)
+		if err != nil {
+			return nil, err
+		}
+		fulfilment := &orderFulfilment{mode: models.FulfilmentDelivery, address: address, zone: zone}
+
+		// окончательно место в слоте занимается в транзакции создания заказа
+		if req.DeliverySlotID != nil {
+			slot, err := s.deliveries.GetSlot(*req.DeliverySlotID)
+			if err != nil {
+				return nil, err
+			}
+			if slot.ZoneID != zone.ID || !slot.IsAvailableAt(time.Now()) {
+				return nil, errs.ErrDeliverySlotUnavailable
+			}
+			fulfilment.slot = slot
+		}
+		return fulfilment, nil
+	}
+
+	if req.PickupPointID == nil {
+		return nil, errs.ErrPickupPointNotFound
+	}
+	if req.DeliverySlotID != nil {
+		return nil, errs.ErrDeliverySlotUnavailable
+	}
+	point, err := s.pickupPoints.GetByID(*req.PickupPointID)
+	if err != nil {
+		return nil, err
+	}
+	if !point.IsActive {
+		return nil, errs.ErrPickupPointNotFound
+	}
+	return &orderFulfilment{mode: models.FulfilmentPickup, point: point}, nil
+}
+
+// newPickupCode - случайный шестизначный код получения заказа.
+func newPickupCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// ExpireUnpaidOrders отменяет заказы, которые не были оплачены за paymentWindow,
+// и возвращает остатки на склад. Возвращает число отменённых заказов.
+func (s *orderService) ExpireUnpaidOrders(paymentWindow time.Duration) (int, error) {
+	orders, err := s.orderRepo.ListPendingCreatedBefore(time.Now().Add(-paymentWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, order := range orders {
+		canceled, err := s.orderRepo.CancelOrder(order.ID, nil, "payment window expired",
+			models.OrderStatusPendingPayment)
+		if err != nil {
+			// заказ мог быть оплачен или отменён между выборкой и блокировкой строки
+			if errors.Is(err, errs.ErrOrderNotCancelable) {
+				continue
+			}
+			return expired, err
+		}
+		s.processRefunds(canceled)
+		expired++
+	}
+	return expired, nil
+}
+
+// processRefunds проводит созданные при отмене возвраты через платёжного провайдера.
+// Неудачный возврат остаётся в статусе failed и виден в заказе, его повторяет RetryFailedRefunds.
+func (s *orderService) processRefunds(order *models.Order) {
+	externalIDs := make(map[uint]string, len(order.Payments))
+	for _, payment := range order.Payments {
+		externalIDs[payment.ID] = payment.ExternalID
+	}
+
+	for i := range order.Refunds {
+		refund := &order.Refunds[i]
+		if refund.Status != models.StatusPending {
+			continue
+		}
+
+		// заказ уже отменён, ошибка сохранения не должна откатывать отмену
+		s.settleRefund(refund, externalIDs[refund.PaymentID])
+	}
+}
+
+func (s *orderService) RetryFailedRefunds() (int, error) {
+	refunds, err := s.orderRepo.ListFailedRefunds()
+	if err != nil {
+		return 0, err
+	}
+
+	retried := 0
+	for i := range refunds {
+		refund := &refunds[i]
+		claimed, err := s.orderRepo.ClaimFailedRefund(refund.ID)
+		if err != nil {
+			return retried, err
+		}
+		if !claimed || refund.Payment == nil {
+			continue
+		}
+
+		refund.Status = models.StatusPending
+		s.settleRefund(refund, refund.Payment.ExternalID)
+		if refund.Status == models.StatusSuccess {
+			retried++
+		}
+	}
+	return retried, nil
+}
+
+// settleRefund проводит возврат и пишет в лог, если его не удалось провести или сохранить.
+// Возврат, статус которого не сохранился, остаётся pending и автоматически не повторяется:
+// провайдер мог уже вернуть деньги, его нужно сверить вручную по external_id.
+func (s *orderService) settleRefund(refund *models.Refund, paymentExternalID string) {
+	if err := settleRefund(s.provider, s.orderRepo, refund, paymentExternalID); err != nil {
+		s.logger.Error("failed to save refund status",
+			"refund_id", refund.ID,
+			"order_id", refund.OrderID,
+			"status", refund.Status,
+			"external_id", refund.ExternalID,
+			"error", err,
+		)
+		return
+	}
+	if refund.Status == models.StatusFailed {
+		s.logger.Warn("refund rejected by provider",
+			"refund_id", refund.ID,
+			"order_id", refund.OrderID,
+		)
+	}
+}
+
+// settleRefund проводит ожидающий возврат через провайдера и сохраняет его статус.
+// Отказ провайдера не ошибка: возврат остаётся failed и виден в заказе.
+func settleRefund(provider PaymentProvider, orderRepo repository.OrderRepository,
+	refund *models.Refund, paymentExternalID string) error {
+
+	externalID, err := provider.RefundPayment(paymentExternalID, refund.Amount)
+	if err != nil {
+		refund.Status = models.StatusFailed
+	} else {
+		refund.Status = models.StatusSuccess
+		refund.ExternalID = externalID
+	}
+	return orderRepo.UpdateRefund(refund)
+}
+
+func orderToResponse(order *models.Order) *dto.OrderResponse {
+	itemsResp := make([]dto.OrderItemResponse, 0, len(order.Items))
 
-	return s.orderRepo.UpdateOrder(orderID, &newStatus)
+	for _, item := range order.Items {
+		batchesResp := make([]dto.OrderItemBatchResponse, 0, len(item.Batches))
+		for _, batch := range item.Batches {
+			batchesResp = append(batchesResp, dto.OrderItemBatchResponse{
+				LotNumber: batch.LotNumber,
+				ExpiresAt: batch.ExpiresAt,
+				Quantity:  batch.Quantity,
+			})
+		}
+		itemsResp = append(itemsResp, dto.OrderItemResponse{
+			MedicineID:   item.MedicineID,
+			MedicineName: item.MedicineName,
+			VariantID:    item.VariantID,
+			VariantName:  item.VariantName,
+			SKU:          item.SKU,
+			Quantity:     item.Quantity,
+			PricePerUnit: item.PricePerUnit,
+			LineTotal:    item.LineTotal,
+			Batches:      batchesResp,
+		})
+	}
+
+	refundsResp := make([]dto.RefundResponse, 0, len(order.Refunds))
+	for _, refund := range order.Refunds {
+		refundsResp = append(refundsResp, dto.RefundResponse{
+			ID:        refund.ID,
+			PaymentID: refund.PaymentID,
+			Amount:    refund.Amount,
+			Status:    refund.Status,
+			CreatedAt: refund.CreatedAt,
+		})
+	}
+
+	var slotResp *dto.OrderDeliverySlotResponse
+	if order.DeliverySlot != nil {
+		slotResp = &dto.OrderDeliverySlotResponse{
+			ID:       order.DeliverySlot.ID,
+			StartsAt: order.DeliverySlot.StartsAt,
+			EndsAt:   order.DeliverySlot.EndsAt,
+		}
+	}
+
+	var shipmentResp *dto.ShipmentResponse
+	if order.Shipment != nil {
+		shipmentResp = toShipmentResponse(order.Shipment)
+	}
+
+	return &dto.OrderResponse{
+		UserID:          order.UserID,
+		Status:          string(order.Status),
+		TotalPrice:      order.TotalPrice,
+		DiscountTotal:   order.DiscountTotal,
+		DeliveryFee:     order.DeliveryFee,
+		DeliveryZoneID:  order.DeliveryZoneID,
+		DeliverySlot:    slotResp,
+		Shipment:        shipmentResp,
+		FinalPrice:      order.FinalPrice,
+		FulfilmentMode:  order.Fulfilment(),
+		DeliveryAddress: order.DeliveryAddress,
+		PickupPointID:   order.PickupPointID,
+		PickupCode:      order.PickupCode,
+		Comment:         order.Comment,
+		Items:           itemsResp,
+		CreatedAt:       order.CreatedAt,
+		Payments:        paymentsToResponse(order.Payments),
+		Refunds:         refundsResp,
+		CanceledAt:      order.CanceledAt,
+		RecallID:        order.RecallID,
+		WarehouseID:     order.WarehouseID,
+		CancelReason:    order.CancelReason,
+		History:         orderEventsToResponse(order.Events),
+	}
+}
+
+func orderEventsToResponse(events []models.OrderEvent) []dto.OrderEventResponse {
+	resp := make([]dto.OrderEventResponse, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, dto.OrderEventResponse{
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			ActorID:    event.ActorID,
+			Reason:     event.Reason,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+	return resp
 }
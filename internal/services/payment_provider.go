@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrProviderPaymentNotFound = errors.New("payment not found in provider")
+
+// PaymentProvider - платёжный шлюз, через который проводятся оплаты заказов.
+type PaymentProvider interface {
+	Name() string
+	CreatePayment(orderID uint, amount int64) (string, error)
+	ConfirmPayment(externalID string) error
+	RefundPayment(externalID string, amount int64) (string, error)
+}
+
+// FakePaymentProvider - локальная заглушка шлюза для разработки и тестов без внешней сети.
+type FakePaymentProvider struct {
+	seq      atomic.Uint64
+	mu       sync.Mutex
+	payments map[string]int64
+}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{payments: make(map[string]int64)}
+}
+
+func (p *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakePaymentProvider) CreatePayment(orderID uint, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("invalid amount %d", amount)
+	}
+
+	externalID := fmt.Sprintf("fake-%d-%d", orderID, p.seq.Add(1))
+
+	p.mu.Lock()
+	p.payments[externalID] = amount
+	p.mu.Unlock()
+
+	return externalID, nil
+}
+
+func (p *FakePaymentProvider) ConfirmPayment(externalID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.payments[externalID]; !ok {
+		return ErrProviderPaymentNotFound
+	}
+	return nil
+}
+
+func (p *FakePaymentProvider) RefundPayment(externalID string, amount int64) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	paid, ok := p.payments[externalID]
+	if !ok {
+		return "", ErrProviderPaymentNotFound
+	}
+	if amount <= 0 || amount > paid {
+		return "", fmt.Errorf("invalid refund amount %d", amount)
+	}
+
+	p.payments[externalID] = paid - amount
+	return fmt.Sprintf("%s-refund-%d", externalID, p.seq.Add(1)), nil
+}
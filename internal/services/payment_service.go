@@ -0,0 +1,172 @@
+package services
+
+import (
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+	"time"
+)
+
+type PaymentService interface {
+	StartPayment(orderID uint, req *dto.PaymentCreateRequest) (*dto.PaymentResponse, error)
+	// ConfirmPayment подтверждает платёж; actorID - администратор, от имени шлюза
+	// отметивший оплату, попадает в историю заказа.
+	ConfirmPayment(orderID, paymentID, actorID uint) (*dto.PaymentResponse, error)
+	FailPayment(orderID, paymentID uint, req *dto.PaymentFailRequest) (*dto.PaymentResponse, error)
+	ListByOrder(orderID uint) ([]dto.PaymentResponse, error)
+}
+
+type paymentService struct {
+	paymentRepo repository.PaymentRepository
+	orderRepo   repository.OrderRepository
+	provider    PaymentProvider
+}
+
+func NewPaymentService(paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository,
+	provider PaymentProvider) PaymentService {
+
+	return &paymentService{paymentRepo: paymentRepo, orderRepo: orderRepo, provider: provider}
+}
+
+func (s *paymentService) StartPayment(orderID uint, req *dto.PaymentCreateRequest) (*dto.PaymentResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.CanChangeOrderStatus(order.Status, models.OrderStatusPaid) {
+		return nil, errs.ErrOrderNotPayable
+	}
+
+	externalID, err := s.provider.CreatePayment(order.ID, order.FinalPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := models.Payment{
+		OrderID:    order.ID,
+		Amount:     order.FinalPrice,
+		Status:     models.StatusPending,
+		Method:     req.Method,
+		Provider:   s.provider.Name(),
+		ExternalID: externalID,
+	}
+
+	if err := s.paymentRepo.Create(&payment); err != nil {
+		return nil, err
+	}
+
+	resp := paymentToResponse(payment)
+	return &resp, nil
+}
+
+func (s *paymentService) ConfirmPayment(orderID, paymentID, actorID uint) (*dto.PaymentResponse, error) {
+	payment, err := s.getPending(orderID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.provider.ConfirmPayment(payment.ExternalID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payment.Status = models.StatusSuccess
+	payment.PaidAT = &now
+
+	refund, err := s.paymentRepo.ConfirmWithOrder(payment, &actorID)
+	if err != nil {
+		return nil, err
+	}
+	if refund != nil {
+		// деньги списаны, но заказ уже нельзя оплатить: сразу возвращаем их покупателю
+		if err := settleRefund(s.provider, s.orderRepo, refund, payment.ExternalID); err != nil {
+			return nil, err
+		}
+		return nil, errs.ErrOrderNotPayable
+	}
+
+	resp := paymentToResponse(*payment)
+	return &resp, nil
+}
+
+func (s *paymentService) FailPayment(orderID, paymentID uint, req *dto.PaymentFailRequest) (*dto.PaymentResponse, error) {
+	payment, err := s.getPending(orderID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.FailureReason = req.Reason
+
+	if err := s.paymentRepo.Fail(payment); err != nil {
+		return nil, err
+	}
+
+	resp := paymentToResponse(*payment)
+	return &resp, nil
+}
+
+func (s *paymentService) ListByOrder(orderID uint) ([]dto.PaymentResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	if _, err := s.orderRepo.GetByID(orderID); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.paymentRepo.ListByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentsToResponse(payments), nil
+}
+
+func (s *paymentService) getPending(orderID, paymentID uint) (*models.Payment, error) {
+	if orderID == 0 || paymentID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.OrderID != orderID {
+		return nil, errs.ErrPaymentNotFound
+	}
+
+	if payment.Status != models.StatusPending {
+		return nil, errs.ErrPaymentAlreadyProcessed
+	}
+	return payment, nil
+}
+
+func paymentToResponse(payment models.Payment) dto.PaymentResponse {
+	return dto.PaymentResponse{
+		ID:            payment.ID,
+		OrderID:       payment.OrderID,
+		Amount:        payment.Amount,
+		Status:        payment.Status,
+		Method:        payment.Method,
+		Provider:      payment.Provider,
+		ExternalID:    payment.ExternalID,
+		FailureReason: payment.FailureReason,
+		PaidAt:        payment.PaidAT,
+		CreatedAt:     payment.CreatedAt,
+	}
+}
+
+func paymentsToResponse(payments []models.Payment) []dto.PaymentResponse {
+	resp := make([]dto.PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		resp = append(resp, paymentToResponse(payment))
+	}
+	return resp
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+)
+
+type PickupPointService interface {
+	Create(req dto.PickupPointCreate) (*models.PickupPoint, error)
+	Update(id uint, req dto.PickupPointUpdate) (*models.PickupPoint, error)
+	List(query dto.PickupPointQuery) ([]models.PickupPoint, error)
+	GetByID(id uint) (*models.PickupPoint, error)
+}
+
+type pickupPointService struct {
+	points     repository.PickupPointRepository
+	warehouses repository.WarehouseRepository
+}
+
+func NewPickupPointService(points repository.PickupPointRepository, warehouses repository.WarehouseRepository) PickupPointService {
+	return &pickupPointService{points: points, warehouses: warehouses}
+}
+
+func (s *pickupPointService) Create(req dto.PickupPointCreate) (*models.PickupPoint, error) {
+	if err := s.ensureWarehouse(req.WarehouseID); err != nil {
+		return nil, err
+	}
+	hours, err := parseOpeningHours(req.OpeningHours)
+	if err != nil {
+		return nil, err
+	}
+
+	point := &models.PickupPoint{
+		WarehouseID:  req.WarehouseID,
+		Name:         strings.TrimSpace(req.Name),
+		City:         strings.TrimSpace(req.City),
+		Address:      strings.TrimSpace(req.Address),
+		Phone:        strings.TrimSpace(req.Phone),
+		OpeningHours: hours,
+		IsActive:     true,
+	}
+	if err := s.points.Create(point); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+func (s *pickupPointService) Update(id uint, req dto.PickupPointUpdate) (*models.PickupPoint, error) {
+	point, err := s.points.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.WarehouseID != nil {
+		if err := s.ensureWarehouse(*req.WarehouseID); err != nil {
+			return nil, err
+		}
+		point.WarehouseID = *req.WarehouseID
+	}
+	if req.Name != nil {
+		point.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.City != nil {
+		point.City = strings.TrimSpace(*req.City)
+	}
+	if req.Address != nil {
+		point.Address = strings.TrimSpace(*req.Address)
+	}
+	if req.Phone != nil {
+		point.Phone = strings.TrimSpace(*req.Phone)
+	}
+	if len(req.OpeningHours) > 0 {
+		if point.OpeningHours, err = parseOpeningHours(req.OpeningHours); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		point.IsActive = *req.IsActive
+	}
+
+	if err := s.points.Update(point); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+func (s *pickupPointService) List(query dto.PickupPointQuery) ([]models.PickupPoint, error) {
+	points, err := s.points.List(strings.TrimSpace(query.City), !query.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	if query.OpenNow {
+		now := time.Now()
+		points = slices.DeleteFunc(points, func(point models.PickupPoint) bool {
+			return !point.IsOpenAt(now)
+		})
+	}
+	return points, nil
+}
+
+func (s *pickupPointService) GetByID(id uint) (*models.PickupPoint, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	return s.points.GetByID(id)
+}
+
+func (s *pickupPointService) ensureWarehouse(id uint) error {
+	warehouse, err := s.warehouses.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !warehouse.IsActive {
+		return errs.ErrWarehouseNotFound
+	}
+	return nil
+}
+
+// parseOpeningHours приводит время к виду HH:MM, чтобы его можно было сравнивать строками.
+func parseOpeningHours(inputs []dto.OpeningHoursInput) ([]models.OpeningHours, error) {
+	hours := make([]models.OpeningHours, 0, len(inputs))
+	for _, input := range inputs {
+		opens, err := time.Parse("15:04", input.Opens)
+		if err != nil {
+			return nil, err
+		}
+		closes, err := time.Parse("15:04", input.Closes)
+		if err != nil {
+			return nil, err
+		}
+		if !closes.After(opens) {
+			return nil, errs.ErrInvalidOpeningHours
+		}
+		hours = append(hours, models.OpeningHours{
+			Weekday: time.Weekday(*input.Weekday),
+			Opens:   opens.Format("15:04"),
+			Closes:  closes.Format("15:04"),
+		})
+	}
+	return hours, nil
+}
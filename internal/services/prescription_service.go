@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PrescriptionService interface {
+	Upload(userID uint, req dto.PrescriptionUploadRequest, filePath string) (*models.Prescription, error)
+	ListByUser(userID uint) ([]models.Prescription, error)
+	ListQueue(status models.PrescriptionStatus) ([]models.Prescription, error)
+	GetByID(id uint) (*models.Prescription, error)
+	Approve(id, reviewerID uint, req dto.PrescriptionReviewRequest) (*models.Prescription, error)
+	Reject(id, reviewerID uint, req dto.PrescriptionReviewRequest) (*models.Prescription, error)
+}
+
+type prescriptionService struct {
+	prescriptionRepo repository.PrescriptionRepository
+	userRepo         repository.UserRepository
+	medicineRepo     repository.MedicineRepository
+}
+
+func NewPrescriptionService(prescriptionRepo repository.PrescriptionRepository, userRepo repository.UserRepository,
+	medicineRepo repository.MedicineRepository) PrescriptionService {
+
+	return &prescriptionService{prescriptionRepo: prescriptionRepo, userRepo: userRepo, medicineRepo: medicineRepo}
+}
+
+func (s *prescriptionService) Upload(userID uint, req dto.PrescriptionUploadRequest, filePath string) (*models.Prescription, error) {
+	if userID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if code == "" && filePath == "" {
+		return nil, errs.ErrInvalidPrescription
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	medicines := make([]models.Medicine, 0, len(req.MedicineIDs))
+	for _, medicineID := range req.MedicineIDs {
+		medicine, err := s.medicineRepo.GetByID(medicineID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errs.ErrMedicineNotFound
+			}
+			return nil, err
+		}
+		medicines = append(medicines, *medicine)
+	}
+
+	prescription := &models.Prescription{
+		UserID:    userID,
+		FilePath:  filePath,
+		Code:      code,
+		Status:    models.PrescriptionStatusPending,
+		Medicines: medicines,
+	}
+
+	if err := s.prescriptionRepo.Create(prescription); err != nil {
+		return nil, err
+	}
+	return prescription, nil
+}
+
+func (s *prescriptionService) ListByUser(userID uint) ([]models.Prescription, error) {
+	if userID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	return s.prescriptionRepo.ListByUser(userID)
+}
+
+func (s *prescriptionService) ListQueue(status models.PrescriptionStatus) ([]models.Prescription, error) {
+	if status == "" {
+		status = models.PrescriptionStatusPending
+	}
+	if !status.IsValid() {
+		return nil, errs.ErrInvalidStatus
+	}
+	return s.prescriptionRepo.ListByStatus(status)
+}
+
+func (s *prescriptionService) GetByID(id uint) (*models.Prescription, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	return s.prescriptionRepo.GetByID(id)
+}
+
+func (s *prescriptionService) Approve(id, reviewerID uint, req dto.PrescriptionReviewRequest) (*models.Prescription, error) {
+	return s.review(id, reviewerID, req, models.PrescriptionStatusApproved)
+}
+
+func (s *prescriptionService) Reject(id, reviewerID uint, req dto.PrescriptionReviewRequest) (*models.Prescription, error) {
+	return s.review(id, reviewerID, req, models.PrescriptionStatusRejected)
+}
+
+func (s *prescriptionService) review(id, reviewerID uint, req dto.PrescriptionReviewRequest,
+	status models.PrescriptionStatus) (*models.Prescription, error) {
+	prescription, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if prescription.Status != models.PrescriptionStatusPending {
+		return nil, errs.ErrPrescriptionReviewed
+	}
+
+	now := time.Now()
+	prescription.Status = status
+	prescription.ReviewedBy = &reviewerID
+	prescription.ReviewedAt = &now
+	prescription.ReviewComment = strings.TrimSpace(req.Comment)
+	if status == models.PrescriptionStatusApproved {
+		prescription.ValidUntil = req.ValidUntil
+	}
+
+	if err := s.prescriptionRepo.Update(prescription); err != nil {
+		return nil, err
+	}
+	return prescription, nil
+}
+
+// ensurePrescriptions проверяет, что на все рецептурные препараты у пользователя
+// есть одобренный действующий рецепт.
+func ensurePrescriptions(prescriptions repository.PrescriptionRepository, userID uint, medicines []*models.Medicine) error {
+	required := make([]uint, 0, len(medicines))
+	for _, medicine := range medicines {
+		if medicine != nil && medicine.PrescriptionRequired {
+			required = append(required, medicine.ID)
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	covered, err := prescriptions.CoveredMedicineIDs(userID, required, time.Now())
+	if err != nil {
+		return err
+	}
+
+	coveredSet := make(map[uint]struct{}, len(covered))
+	for _, id := range covered {
+		coveredSet[id] = struct{}{}
+	}
+	for _, medicine := range medicines {
+		if medicine == nil || !medicine.PrescriptionRequired {
+			continue
+		}
+		if _, ok := coveredSet[medicine.ID]; !ok {
+			return fmt.Errorf("%w: %s", errs.ErrPrescriptionRequired, medicine.Name)
+		}
+	}
+	return nil
+}
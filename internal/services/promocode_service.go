@@ -0,0 +1,217 @@
+package services
+
+import (
+	"strings"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+	"time"
+)
+
+type PromocodeService interface {
+	Create(req dto.PromocodeCreate) (*models.Promocode, error)
+	List() ([]models.Promocode, error)
+	GetByID(id uint) (*models.Promocode, error)
+	Update(id uint, req dto.PromocodeUpdate) (*models.Promocode, error)
+	Delete(id uint) error
+	ListUsages(id uint) ([]models.PromocodeUsage, error)
+
+	Apply(userID uint, code string, items []models.CartItem) (*models.Promocode, int64, error)
+}
+
+type promocodeService struct {
+	promocodeRepo repository.PromocodeRepository
+}
+
+func NewPromocodeService(promocodeRepo repository.PromocodeRepository) PromocodeService {
+	return &promocodeService{promocodeRepo: promocodeRepo}
+}
+
+func (s *promocodeService) Create(req dto.PromocodeCreate) (*models.Promocode, error) {
+	promocode := &models.Promocode{
+		Code:          normalizePromocode(req.Code),
+		DiscountType:  req.DiscountType,
+		Value:         req.Value,
+		MinOrderTotal: req.MinOrderTotal,
+		ValidFrom:     req.ValidFrom,
+		ValidUntil:    req.ValidUntil,
+		UsageLimit:    req.UsageLimit,
+		PerUserLimit:  req.PerUserLimit,
+		IsActive:      true,
+		CategoryID:    req.CategoryID,
+		MedicineID:    req.MedicineID,
+	}
+
+	if promocode.Code == "" {
+		return nil, errs.ErrInvalidPromocode
+	}
+	if err := validatePromocode(promocode); err != nil {
+		return nil, err
+	}
+
+	if err := s.promocodeRepo.Create(promocode); err != nil {
+		return nil, err
+	}
+	return promocode, nil
+}
+
+func (s *promocodeService) List() ([]models.Promocode, error) {
+	return s.promocodeRepo.List()
+}
+
+func (s *promocodeService) GetByID(id uint) (*models.Promocode, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	return s.promocodeRepo.GetByID(id)
+}
+
+func (s *promocodeService) Update(id uint, req dto.PromocodeUpdate) (*models.Promocode, error) {
+	promocode, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DiscountType != nil {
+		promocode.DiscountType = *req.DiscountType
+	}
+	if req.Value != nil {
+		promocode.Value = *req.Value
+	}
+	if req.MinOrderTotal != nil {
+		promocode.MinOrderTotal = *req.MinOrderTotal
+	}
+	if req.ValidFrom != nil {
+		promocode.ValidFrom = req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		promocode.ValidUntil = req.ValidUntil
+	}
+	if req.UsageLimit != nil {
+		promocode.UsageLimit = *req.UsageLimit
+	}
+	if req.PerUserLimit != nil {
+		promocode.PerUserLimit = *req.PerUserLimit
+	}
+	if req.IsActive != nil {
+		promocode.IsActive = *req.IsActive
+	}
+	if req.CategoryID != nil {
+		promocode.CategoryID = req.CategoryID
+	}
+	if req.MedicineID != nil {
+		promocode.MedicineID = req.MedicineID
+	}
+
+	if err := validatePromocode(promocode); err != nil {
+		return nil, err
+	}
+
+	if err := s.promocodeRepo.Update(promocode); err != nil {
+		return nil, err
+	}
+	return promocode, nil
+}
+
+func (s *promocodeService) Delete(id uint) error {
+	if id == 0 {
+		return errs.ErrInvalidID
+	}
+	return s.promocodeRepo.Delete(id)
+}
+
+func (s *promocodeService) ListUsages(id uint) ([]models.PromocodeUsage, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.promocodeRepo.ListUsages(id)
+}
+
+// Apply проверяет промокод для корзины пользователя и возвращает размер скидки.
+// Лимиты использования окончательно проверяются при создании заказа в транзакции.
+func (s *promocodeService) Apply(userID uint, code string, items []models.CartItem) (*models.Promocode, int64, error) {
+	promocode, err := s.promocodeRepo.GetByCode(normalizePromocode(code))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !promocode.IsValidAt(time.Now()) {
+		return nil, 0, errs.ErrPromocodeInactive
+	}
+
+	if promocode.UsageLimit > 0 && promocode.UsedCount >= promocode.UsageLimit {
+		return nil, 0, errs.ErrPromocodeUsageLimit
+	}
+
+	if promocode.PerUserLimit > 0 {
+		used, err := s.promocodeRepo.CountUsagesByUser(promocode.ID, userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if used >= int64(promocode.PerUserLimit) {
+			return nil, 0, errs.ErrPromocodeUsageLimit
+		}
+	}
+
+	var total, eligible int64
+	for _, item := range items {
+		lineTotal := int64(item.Quantity) * item.PricePerUnit
+		total += lineTotal
+		if promocodeCoversItem(promocode, item) {
+			eligible += lineTotal
+		}
+	}
+
+	if total < promocode.MinOrderTotal || eligible == 0 {
+		return nil, 0, errs.ErrPromocodeNotApplicable
+	}
+
+	var discount int64
+	switch promocode.DiscountType {
+	case models.DiscountTypePercent:
+		discount = eligible * promocode.Value / 100
+	case models.DiscountTypeFixed:
+		discount = promocode.Value
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+
+	return promocode, discount, nil
+}
+
+func promocodeCoversItem(promocode *models.Promocode, item models.CartItem) bool {
+	if promocode.MedicineID != nil {
+		return item.MedicineID == *promocode.MedicineID
+	}
+	if promocode.CategoryID != nil {
+		return item.Medicine != nil && item.Medicine.CategoryID != nil &&
+			*item.Medicine.CategoryID == *promocode.CategoryID
+	}
+	return true
+}
+
+func validatePromocode(promocode *models.Promocode) error {
+	switch promocode.DiscountType {
+	case models.DiscountTypePercent:
+		if promocode.Value <= 0 || promocode.Value > 100 {
+			return errs.ErrInvalidPromocode
+		}
+	case models.DiscountTypeFixed:
+		if promocode.Value <= 0 {
+			return errs.ErrInvalidPromocode
+		}
+	default:
+		return errs.ErrInvalidPromocode
+	}
+
+	if promocode.ValidFrom != nil && promocode.ValidUntil != nil && promocode.ValidUntil.Before(*promocode.ValidFrom) {
+		return errs.ErrInvalidPromocode
+	}
+	return nil
+}
+
+func normalizePromocode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
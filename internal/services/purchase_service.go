@@ -0,0 +1,289 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+)
+
+type PurchaseService interface {
+	CreateSupplier(req dto.SupplierCreate) (*models.Supplier, error)
+	UpdateSupplier(id uint, req dto.SupplierUpdate) (*models.Supplier, error)
+	ListSuppliers() ([]models.Supplier, error)
+
+	CreateOrder(actorID uint, req dto.PurchaseOrderCreate) (*dto.PurchaseOrderResponse, error)
+	UpdateOrder(id uint, req dto.PurchaseOrderUpdate) (*dto.PurchaseOrderResponse, error)
+	SendOrder(id uint) (*dto.PurchaseOrderResponse, error)
+	ReceiveOrder(id, actorID uint, req dto.PurchaseReceiveRequest) (*dto.PurchaseOrderResponse, error)
+	GetOrder(id uint) (*dto.PurchaseOrderResponse, error)
+	ListOrders(query dto.PurchaseOrderQuery) ([]dto.PurchaseOrderResponse, error)
+}
+
+type purchaseService struct {
+	suppliers  repository.SupplierRepository
+	orders     repository.PurchaseOrderRepository
+	variants   repository.MedicineVariantRepository
+	warehouses repository.WarehouseRepository
+}
+
+func NewPurchaseService(suppliers repository.SupplierRepository, orders repository.PurchaseOrderRepository,
+	variants repository.MedicineVariantRepository, warehouses repository.WarehouseRepository) PurchaseService {
+
+	return &purchaseService{suppliers: suppliers, orders: orders, variants: variants, warehouses: warehouses}
+}
+
+func (s *purchaseService) CreateSupplier(req dto.SupplierCreate) (*models.Supplier, error) {
+	supplier := &models.Supplier{
+		Name:     strings.TrimSpace(req.Name),
+		TaxID:    trimmedOrNil(req.TaxID),
+		Email:    strings.TrimSpace(req.Email),
+		Phone:    strings.TrimSpace(req.Phone),
+		Address:  strings.TrimSpace(req.Address),
+		IsActive: true,
+	}
+	if err := s.suppliers.Create(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func (s *purchaseService) UpdateSupplier(id uint, req dto.SupplierUpdate) (*models.Supplier, error) {
+	supplier, err := s.suppliers.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		supplier.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.TaxID != nil {
+		supplier.TaxID = trimmedOrNil(req.TaxID)
+	}
+	if req.Email != nil {
+		supplier.Email = strings.TrimSpace(*req.Email)
+	}
+	if req.Phone != nil {
+		supplier.Phone = strings.TrimSpace(*req.Phone)
+	}
+	if req.Address != nil {
+		supplier.Address = strings.TrimSpace(*req.Address)
+	}
+	if req.IsActive != nil {
+		supplier.IsActive = *req.IsActive
+	}
+
+	if err := s.suppliers.Update(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func (s *purchaseService) ListSuppliers() ([]models.Supplier, error) {
+	return s.suppliers.List()
+}
+
+func (s *purchaseService) CreateOrder(actorID uint, req dto.PurchaseOrderCreate) (*dto.PurchaseOrderResponse, error) {
+	supplier, err := s.suppliers.GetByID(req.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if !supplier.IsActive {
+		return nil, errs.ErrSupplierNotFound
+	}
+	warehouse, err := s.warehouses.GetByID(req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if !warehouse.IsActive {
+		return nil, errs.ErrWarehouseNotFound
+	}
+
+	expectedAt, err := parseOptionalDate(req.ExpectedAt)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.buildLines(req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.PurchaseOrder{
+		SupplierID:  supplier.ID,
+		WarehouseID: warehouse.ID,
+		Status:      models.PurchaseOrderDraft,
+		ExpectedAt:  expectedAt,
+		Comment:     strings.TrimSpace(req.Comment),
+		CreatedBy:   &actorID,
+		Lines:       lines,
+	}
+	if err := s.orders.Create(order); err != nil {
+		return nil, err
+	}
+	return s.GetOrder(order.ID)
+}
+
+func (s *purchaseService) UpdateOrder(id uint, req dto.PurchaseOrderUpdate) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.orders.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return nil, errs.ErrPurchaseOrderNotDraft
+	}
+
+	if req.ExpectedAt != nil {
+		if order.ExpectedAt, err = parseOptionalDate(req.ExpectedAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.Comment != nil {
+		order.Comment = strings.TrimSpace(*req.Comment)
+	}
+
+	var lines []models.PurchaseOrderLine
+	if len(req.Lines) > 0 {
+		if lines, err = s.buildLines(req.Lines); err != nil {
+			return nil, err
+		}
+	}
+
+	order.Supplier, order.Warehouse, order.Lines = nil, nil, nil
+	if err := s.orders.Update(order, lines); err != nil {
+		return nil, err
+	}
+	return s.GetOrder(order.ID)
+}
+
+func (s *purchaseService) SendOrder(id uint) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.orders.Send(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toPurchaseOrderResponse(order)
+	return &resp, nil
+}
+
+func (s *purchaseService) ReceiveOrder(id, actorID uint, req dto.PurchaseReceiveRequest) (*dto.PurchaseOrderResponse, error) {
+	now := time.Now()
+	receipts := make([]repository.PurchaseReceipt, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		expiresAt, err := time.Parse(time.DateOnly, line.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		if !expiresAt.After(now) {
+			return nil, errs.ErrBatchExpired
+		}
+		receipts = append(receipts, repository.PurchaseReceipt{
+			LineID:    line.LineID,
+			LotNumber: strings.TrimSpace(line.LotNumber),
+			ExpiresAt: expiresAt,
+			Quantity:  line.Quantity,
+		})
+	}
+
+	order, err := s.orders.Receive(id, receipts, &actorID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toPurchaseOrderResponse(order)
+	return &resp, nil
+}
+
+func (s *purchaseService) GetOrder(id uint) (*dto.PurchaseOrderResponse, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	order, err := s.orders.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toPurchaseOrderResponse(order)
+	return &resp, nil
+}
+
+func (s *purchaseService) ListOrders(query dto.PurchaseOrderQuery) ([]dto.PurchaseOrderResponse, error) {
+	orders, err := s.orders.List(repository.PurchaseOrderFilter{
+		Status:      models.PurchaseOrderStatus(query.Status),
+		SupplierID:  query.SupplierID,
+		WarehouseID: query.WarehouseID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.PurchaseOrderResponse, 0, len(orders))
+	for i := range orders {
+		result = append(result, toPurchaseOrderResponse(&orders[i]))
+	}
+	return result, nil
+}
+
+func (s *purchaseService) buildLines(inputs []dto.PurchaseOrderLineInput) ([]models.PurchaseOrderLine, error) {
+	lines := make([]models.PurchaseOrderLine, 0, len(inputs))
+	for _, input := range inputs {
+		variant, err := s.variants.GetByID(input.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, models.PurchaseOrderLine{
+			VariantID:  variant.ID,
+			MedicineID: variant.MedicineID,
+			Quantity:   input.Quantity,
+			UnitCost:   input.UnitCost,
+		})
+	}
+	return lines, nil
+}
+
+func parseOptionalDate(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+func toPurchaseOrderResponse(order *models.PurchaseOrder) dto.PurchaseOrderResponse {
+	var total uint64
+	lines := make([]dto.PurchaseOrderLineResponse, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		lineTotal := uint64(line.Quantity) * line.UnitCost
+		total += lineTotal
+		lines = append(lines, dto.PurchaseOrderLineResponse{
+			ID:               line.ID,
+			VariantID:        line.VariantID,
+			MedicineID:       line.MedicineID,
+			Quantity:         line.Quantity,
+			ReceivedQuantity: line.ReceivedQuantity,
+			UnitCost:         line.UnitCost,
+			LineTotal:        lineTotal,
+		})
+	}
+
+	resp := dto.PurchaseOrderResponse{
+		ID:          order.ID,
+		SupplierID:  order.SupplierID,
+		WarehouseID: order.WarehouseID,
+		Status:      order.Status,
+		ExpectedAt:  order.ExpectedAt,
+		Comment:     order.Comment,
+		TotalCost:   total,
+		CreatedBy:   order.CreatedBy,
+		CreatedAt:   order.CreatedAt,
+		SentAt:      order.SentAt,
+		ReceivedAt:  order.ReceivedAt,
+		Lines:       lines,
+	}
+	if order.Supplier != nil {
+		resp.SupplierName = order.Supplier.Name
+	}
+	return resp
+}
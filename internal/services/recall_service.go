@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type RecallService interface {
+	Create(actorID uint, req dto.RecallCreateRequest) (*dto.RecallResponse, error)
+	GetByID(id uint) (*dto.RecallResponse, error)
+	List() ([]dto.RecallResponse, error)
+}
+
+type recallService struct {
+	recalls   repository.RecallRepository
+	medicines repository.MedicineRepository
+}
+
+func NewRecallService(recalls repository.RecallRepository, medicines repository.MedicineRepository) RecallService {
+	return &recallService{recalls: recalls, medicines: medicines}
+}
+
+func (s *recallService) Create(actorID uint, req dto.RecallCreateRequest) (*dto.RecallResponse, error) {
+	if _, err := s.medicines.GetByID(req.MedicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrMedicineNotFound
+		}
+		return nil, err
+	}
+
+	lotNumbers := make([]string, 0, len(req.LotNumbers))
+	for _, lot := range req.LotNumbers {
+		lotNumbers = append(lotNumbers, strings.TrimSpace(lot))
+	}
+	lotNumbers = uniqueStrings(lotNumbers)
+
+	recall := &models.Recall{
+		MedicineID: req.MedicineID,
+		Reason:     strings.TrimSpace(req.Reason),
+		CreatedBy:  &actorID,
+		Lots:       make([]models.RecallLot, 0, len(lotNumbers)),
+	}
+	for _, lot := range lotNumbers {
+		recall.Lots = append(recall.Lots, models.RecallLot{LotNumber: lot})
+	}
+
+	if err := s.recalls.Create(recall); err != nil {
+		return nil, err
+	}
+	// перечитываем отзыв, чтобы в списке оповещения были контакты покупателей
+	return s.GetByID(recall.ID)
+}
+
+func (s *recallService) GetByID(id uint) (*dto.RecallResponse, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	recall, err := s.recalls.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toRecallResponse(recall)
+	resp.Customers = recallCustomers(recall.Notifications)
+	return &resp, nil
+}
+
+func (s *recallService) List() ([]dto.RecallResponse, error) {
+	recalls, err := s.recalls.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.RecallResponse, 0, len(recalls))
+	for i := range recalls {
+		result = append(result, toRecallResponse(&recalls[i]))
+	}
+	return result, nil
+}
+
+func toRecallResponse(recall *models.Recall) dto.RecallResponse {
+	lotNumbers := make([]string, 0, len(recall.Lots))
+	for _, lot := range recall.Lots {
+		lotNumbers = append(lotNumbers, lot.LotNumber)
+	}
+	slices.Sort(lotNumbers)
+
+	resp := dto.RecallResponse{
+		ID:         recall.ID,
+		MedicineID: recall.MedicineID,
+		LotNumbers: lotNumbers,
+		Reason:     recall.Reason,
+		CreatedBy:  recall.CreatedBy,
+		CreatedAt:  recall.CreatedAt,
+	}
+	if recall.Medicine != nil {
+		resp.MedicineName = recall.Medicine.Name
+	}
+	return resp
+}
+
+// recallCustomers группирует записи оповещения по покупателям; записи уже
+// отсортированы по user_id.
+func recallCustomers(notifications []models.RecallNotification) []dto.RecallCustomerResponse {
+	customers := make([]dto.RecallCustomerResponse, 0)
+	for _, n := range notifications {
+		if len(customers) == 0 || customers[len(customers)-1].UserID != n.UserID {
+			customer := dto.RecallCustomerResponse{UserID: n.UserID}
+			if n.User != nil {
+				customer.FullName = n.User.FullName
+				customer.Email = n.User.Email
+				customer.Phone = n.User.Phone
+			}
+			customers = append(customers, customer)
+		}
+
+		customer := &customers[len(customers)-1]
+		switch n.Source {
+		case models.RecallNoticeCart:
+			customer.InCart = true
+		case models.RecallNoticeOrder:
+			if n.OrderID == nil {
+				continue
+			}
+			customer.Orders = append(customer.Orders, dto.RecallOrderResponse{
+				OrderID: *n.OrderID,
+				Status:  n.OrderStatus,
+				Flagged: slices.Contains(models.RecallFlaggedOrderStatuses, n.OrderStatus),
+			})
+		}
+	}
+	return customers
+}
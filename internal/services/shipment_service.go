@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type ShipmentService interface {
+	Create(orderID, actorID uint, req dto.ShipmentCreate) (*dto.ShipmentResponse, error)
+	Get(id uint) (*dto.ShipmentResponse, error)
+	GetByOrder(orderID uint) (*dto.ShipmentResponse, error)
+	Update(id uint, req dto.ShipmentUpdate) (*dto.ShipmentResponse, error)
+	Dispatch(id, actorID uint) (*dto.ShipmentResponse, error)
+	// RecordAttempt записывает попытку вручения. Курьер может отмечать только
+	// назначенные ему отправления.
+	RecordAttempt(id, actorID uint, actorRole models.Role, req dto.DeliveryAttemptRequest) (*dto.ShipmentResponse, error)
+	CourierShipments(courierID uint, query dto.CourierShipmentQuery) ([]dto.ShipmentResponse, error)
+}
+
+type shipmentService struct {
+	shipments repository.ShipmentRepository
+	users     repository.UserRepository
+}
+
+func NewShipmentService(shipments repository.ShipmentRepository, users repository.UserRepository) ShipmentService {
+	return &shipmentService{shipments: shipments, users: users}
+}
+
+func (s *shipmentService) Create(orderID, actorID uint, req dto.ShipmentCreate) (*dto.ShipmentResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	if err := s.ensureCourier(req.CourierID); err != nil {
+		return nil, err
+	}
+
+	shipment := &models.Shipment{
+		OrderID:        orderID,
+		CourierID:      req.CourierID,
+		Carrier:        strings.TrimSpace(req.Carrier),
+		TrackingNumber: trimmedOrNil(req.TrackingNumber),
+		CreatedBy:      &actorID,
+	}
+	if err := s.shipments.Create(shipment); err != nil {
+		return nil, err
+	}
+	return s.Get(shipment.ID)
+}
+
+func (s *shipmentService) Get(id uint) (*dto.ShipmentResponse, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	shipment, err := s.shipments.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toShipmentResponse(shipment), nil
+}
+
+func (s *shipmentService) GetByOrder(orderID uint) (*dto.ShipmentResponse, error) {
+	if orderID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	shipment, err := s.shipments.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return toShipmentResponse(shipment), nil
+}
+
+func (s *shipmentService) Update(id uint, req dto.ShipmentUpdate) (*dto.ShipmentResponse, error) {
+	shipment, err := s.shipments.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CourierID != nil {
+		if err := s.ensureCourier(req.CourierID); err != nil {
+			return nil, err
+		}
+		shipment.CourierID = req.CourierID
+	}
+	if req.Carrier != nil {
+		shipment.Carrier = strings.TrimSpace(*req.Carrier)
+	}
+	if req.TrackingNumber != nil {
+		shipment.TrackingNumber = trimmedOrNil(req.TrackingNumber)
+	}
+
+	if err := s.shipments.Update(shipment); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+func (s *shipmentService) Dispatch(id, actorID uint) (*dto.ShipmentResponse, error) {
+	shipment, err := s.shipments.Dispatch(id, &actorID)
+	if err != nil {
+		return nil, err
+	}
+	return toShipmentResponse(shipment), nil
+}
+
+func (s *shipmentService) RecordAttempt(id, actorID uint, actorRole models.Role, req dto.DeliveryAttemptRequest) (*dto.ShipmentResponse, error) {
+	shipment, err := s.shipments.RecordAttempt(id, &models.DeliveryAttempt{
+		Outcome: req.Outcome,
+		Comment: strings.TrimSpace(req.Comment),
+	}, &actorID, actorRole == models.RoleCourier)
+	if err != nil {
+		return nil, err
+	}
+	return toShipmentResponse(shipment), nil
+}
+
+func (s *shipmentService) CourierShipments(courierID uint, query dto.CourierShipmentQuery) ([]dto.ShipmentResponse, error) {
+	shipments, err := s.shipments.ListByCourier(courierID, !query.All)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.ShipmentResponse, 0, len(shipments))
+	for i := range shipments {
+		result = append(result, *toShipmentResponse(&shipments[i]))
+	}
+	return result, nil
+}
+
+func (s *shipmentService) ensureCourier(courierID *uint) error {
+	if courierID == nil {
+		return nil
+	}
+	user, err := s.users.GetByID(*courierID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrInvalidCourier
+		}
+		return err
+	}
+	if user.Role != models.RoleCourier {
+		return errs.ErrInvalidCourier
+	}
+	return nil
+}
+
+func toShipmentResponse(shipment *models.Shipment) *dto.ShipmentResponse {
+	resp := &dto.ShipmentResponse{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		CourierID:      shipment.CourierID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		Status:         shipment.Status,
+		CreatedAt:      shipment.CreatedAt,
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+	}
+	if shipment.Order != nil {
+		resp.DeliveryAddress = shipment.Order.DeliveryAddress
+	}
+	for _, attempt := range shipment.Attempts {
+		resp.Attempts = append(resp.Attempts, dto.DeliveryAttemptResponse{
+			ID:        attempt.ID,
+			CourierID: attempt.CourierID,
+			Outcome:   attempt.Outcome,
+			Comment:   attempt.Comment,
+			CreatedAt: attempt.CreatedAt,
+		})
+	}
+	return resp
+}
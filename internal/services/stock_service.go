@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type StockService interface {
+	ReceiveBatch(medicineID, variantID, actorID uint, req dto.StockBatchCreateRequest) (*dto.StockBatchResponse, error)
+	ListBatches(medicineID uint) ([]dto.StockBatchResponse, error)
+	// RecordMovement списывает или корректирует остаток партии через журнал движений.
+	RecordMovement(medicineID, batchID, actorID uint, req dto.StockMovementCreateRequest) (*dto.StockMovementResponse, error)
+	ListMovements(medicineID uint, query dto.StockMovementQuery) (*dto.StockMovementListResponse, error)
+	// RefreshExpiredStock убирает из остатков партии с истёкшим сроком годности.
+	RefreshExpiredStock() (int, error)
+}
+
+type stockService struct {
+	batches    repository.StockBatchRepository
+	variants   repository.MedicineVariantRepository
+	medicines  repository.MedicineRepository
+	warehouses repository.WarehouseRepository
+	movements  repository.StockMovementRepository
+}
+
+func NewStockService(batches repository.StockBatchRepository, variants repository.MedicineVariantRepository,
+	medicines repository.MedicineRepository, warehouses repository.WarehouseRepository,
+	movements repository.StockMovementRepository) StockService {
+
+	return &stockService{batches: batches, variants: variants, medicines: medicines, warehouses: warehouses,
+		movements: movements}
+}
+
+func (s *stockService) ReceiveBatch(medicineID, variantID, actorID uint, req dto.StockBatchCreateRequest) (*dto.StockBatchResponse, error) {
+	if medicineID == 0 || variantID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	variant, err := s.variants.GetByID(variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.MedicineID != medicineID {
+		return nil, errs.ErrVariantNotFound
+	}
+	if _, err := s.warehouses.GetByID(req.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := time.Parse(time.DateOnly, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil, errs.ErrBatchExpired
+	}
+
+	batch := &models.StockBatch{
+		VariantID:   variant.ID,
+		WarehouseID: req.WarehouseID,
+		LotNumber:   strings.TrimSpace(req.LotNumber),
+		ExpiresAt:   &expiresAt,
+		Quantity:    req.Quantity,
+	}
+	if err := s.batches.Create(batch, &actorID); err != nil {
+		return nil, err
+	}
+
+	resp := toStockBatchResponse(batch, now)
+	return &resp, nil
+}
+
+func (s *stockService) ListBatches(medicineID uint) ([]dto.StockBatchResponse, error) {
+	if err := s.ensureMedicine(medicineID); err != nil {
+		return nil, err
+	}
+
+	batches, err := s.batches.ListByMedicine(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]dto.StockBatchResponse, 0, len(batches))
+	for i := range batches {
+		result = append(result, toStockBatchResponse(&batches[i], now))
+	}
+	return result, nil
+}
+
+const (
+	defaultMovementPageSize = 50
+	maxMovementPageSize     = 200
+)
+
+func (s *stockService) RecordMovement(medicineID, batchID, actorID uint, req dto.StockMovementCreateRequest) (*dto.StockMovementResponse, error) {
+	if medicineID == 0 || batchID == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	batch, err := s.batches.GetByID(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.MedicineID != medicineID {
+		return nil, errs.ErrBatchNotFound
+	}
+
+	delta := req.Quantity
+	if req.Type == models.StockMovementWriteOff {
+		if req.Quantity < 0 {
+			return nil, errs.ErrInvalidStockMovement
+		}
+		delta = -req.Quantity
+	}
+
+	movement := &models.StockMovement{
+		Type:    req.Type,
+		Delta:   delta,
+		Reason:  strings.TrimSpace(req.Reason),
+		ActorID: &actorID,
+	}
+	if err := s.batches.ApplyMovement(batch.ID, movement); err != nil {
+		return nil, err
+	}
+
+	resp := toStockMovementResponse(movement, nil)
+	return &resp, nil
+}
+
+func (s *stockService) ListMovements(medicineID uint, query dto.StockMovementQuery) (*dto.StockMovementListResponse, error) {
+	if err := s.ensureMedicine(medicineID); err != nil {
+		return nil, err
+	}
+
+	filter := repository.StockMovementFilter{
+		MedicineID:  medicineID,
+		VariantID:   query.VariantID,
+		WarehouseID: query.WarehouseID,
+		Type:        models.StockMovementType(query.Type),
+		From:        query.From,
+		To:          query.To,
+		Offset:      query.Offset,
+		Limit:       query.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultMovementPageSize
+	}
+	if filter.Limit > maxMovementPageSize {
+		filter.Limit = maxMovementPageSize
+	}
+
+	entries, total, err := s.movements.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.movements.Balance(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.StockMovementResponse, 0, len(entries))
+	for i := range entries {
+		items = append(items, toStockMovementResponse(&entries[i].StockMovement, &entries[i].Balance))
+	}
+	return &dto.StockMovementListResponse{
+		Items:   items,
+		Total:   total,
+		Balance: balance,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+func (s *stockService) ensureMedicine(medicineID uint) error {
+	if medicineID == 0 {
+		return errs.ErrInvalidID
+	}
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *stockService) RefreshExpiredStock() (int, error) {
+	return s.batches.RefreshExpiredStock(time.Now())
+}
+
+func toStockBatchResponse(batch *models.StockBatch, now time.Time) dto.StockBatchResponse {
+	return dto.StockBatchResponse{
+		ID:               batch.ID,
+		VariantID:        batch.VariantID,
+		MedicineID:       batch.MedicineID,
+		WarehouseID:      batch.WarehouseID,
+		LotNumber:        batch.LotNumber,
+		ExpiresAt:        batch.ExpiresAt,
+		Quantity:         batch.Quantity,
+		ReceivedQuantity: batch.ReceivedQuantity,
+		Expired:          batch.ExpiresAt != nil && !batch.ExpiresAt.After(now),
+		RecallID:         batch.RecallID,
+		CreatedAt:        batch.CreatedAt,
+	}
+}
+
+func toStockMovementResponse(movement *models.StockMovement, balance *int) dto.StockMovementResponse {
+	return dto.StockMovementResponse{
+		ID:           movement.ID,
+		CreatedAt:    movement.CreatedAt,
+		BatchID:      movement.BatchID,
+		VariantID:    movement.VariantID,
+		WarehouseID:  movement.WarehouseID,
+		Type:         movement.Type,
+		Delta:        movement.Delta,
+		Balance:      balance,
+		Reason:       movement.Reason,
+		ActorID:      movement.ActorID,
+		OrderID:      movement.OrderID,
+		DocumentType: movement.DocumentType,
+		DocumentID:   movement.DocumentID,
+	}
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+)
+
+type StocktakeService interface {
+	Create(actorID uint, req dto.StocktakeCreate) (*dto.StocktakeResponse, error)
+	Get(id uint) (*dto.StocktakeResponse, error)
+	List(query dto.StocktakeQuery) ([]dto.StocktakeResponse, error)
+	Count(id, actorID uint, req dto.StocktakeCountRequest) (*dto.StocktakeResponse, error)
+	Variance(id uint, query dto.StocktakeVarianceQuery) (*dto.StocktakeVarianceResponse, error)
+	Apply(id, actorID uint) (*dto.StocktakeResponse, error)
+	Cancel(id uint) (*dto.StocktakeResponse, error)
+}
+
+type stocktakeService struct {
+	stocktakes repository.StocktakeRepository
+	warehouses repository.WarehouseRepository
+}
+
+func NewStocktakeService(stocktakes repository.StocktakeRepository, warehouses repository.WarehouseRepository) StocktakeService {
+	return &stocktakeService{stocktakes: stocktakes, warehouses: warehouses}
+}
+
+func (s *stocktakeService) Create(actorID uint, req dto.StocktakeCreate) (*dto.StocktakeResponse, error) {
+	warehouse, err := s.warehouses.GetByID(req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if !warehouse.IsActive {
+		return nil, errs.ErrWarehouseNotFound
+	}
+
+	stocktake := &models.Stocktake{
+		WarehouseID: warehouse.ID,
+		Status:      models.StocktakeOpen,
+		Comment:     strings.TrimSpace(req.Comment),
+		CreatedBy:   &actorID,
+	}
+	if err := s.stocktakes.Create(stocktake, req.MedicineIDs); err != nil {
+		return nil, err
+	}
+	return s.Get(stocktake.ID)
+}
+
+func (s *stocktakeService) Get(id uint) (*dto.StocktakeResponse, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	stocktake, err := s.stocktakes.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toStocktakeResponse(stocktake)
+	return &resp, nil
+}
+
+func (s *stocktakeService) List(query dto.StocktakeQuery) ([]dto.StocktakeResponse, error) {
+	stocktakes, err := s.stocktakes.List(repository.StocktakeFilter{
+		Status:      models.StocktakeStatus(query.Status),
+		WarehouseID: query.WarehouseID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.StocktakeResponse, 0, len(stocktakes))
+	for i := range stocktakes {
+		result = append(result, toStocktakeResponse(&stocktakes[i]))
+	}
+	return result, nil
+}
+
+func (s *stocktakeService) Count(id, actorID uint, req dto.StocktakeCountRequest) (*dto.StocktakeResponse, error) {
+	stocktake, err := s.stocktakes.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if stocktake.Status != models.StocktakeOpen {
+		return nil, errs.ErrStocktakeNotOpen
+	}
+
+	counts, err := resolveCounts(stocktake.Lines, req.Counts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.stocktakes.SaveCounts(id, counts, &actorID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toStocktakeResponse(updated)
+	return &resp, nil
+}
+
+func (s *stocktakeService) Variance(id uint, query dto.StocktakeVarianceQuery) (*dto.StocktakeVarianceResponse, error) {
+	if id == 0 {
+		return nil, errs.ErrInvalidID
+	}
+	stocktake, err := s.stocktakes.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.StocktakeVarianceResponse{
+		StocktakeID:  stocktake.ID,
+		WarehouseID:  stocktake.WarehouseID,
+		Status:       stocktake.Status,
+		TotalBatches: len(stocktake.Lines),
+		Medicines:    []dto.StocktakeMedicineVariance{},
+	}
+
+	byMedicine := make(map[uint]int)
+	for _, line := range stocktake.Lines {
+		idx, ok := byMedicine[line.MedicineID]
+		if !ok {
+			idx = len(report.Medicines)
+			byMedicine[line.MedicineID] = idx
+			item := dto.StocktakeMedicineVariance{MedicineID: line.MedicineID, Batches: []dto.StocktakeLineResponse{}}
+			if line.Medicine != nil {
+				item.Name = line.Medicine.Name
+			}
+			report.Medicines = append(report.Medicines, item)
+		}
+		item := &report.Medicines[idx]
+		item.ExpectedQuantity += line.ExpectedQuantity
+
+		variance := line.Variance()
+		if variance == nil {
+			item.UncountedBatches++
+		} else {
+			report.CountedBatches++
+			item.CountedQuantity += *line.CountedQuantity
+			item.Variance += *variance
+			if *variance < 0 {
+				report.Shortage -= *variance
+			} else {
+				report.Surplus += *variance
+			}
+		}
+
+		if !query.OnlyDiscrepancies || (variance != nil && *variance != 0) {
+			item.Batches = append(item.Batches, toStocktakeLineResponse(line))
+		}
+	}
+
+	if query.OnlyDiscrepancies {
+		report.Medicines = slices.DeleteFunc(report.Medicines, func(item dto.StocktakeMedicineVariance) bool {
+			return len(item.Batches) == 0
+		})
+	}
+	return report, nil
+}
+
+func (s *stocktakeService) Apply(id, actorID uint) (*dto.StocktakeResponse, error) {
+	stocktake, err := s.stocktakes.Apply(id, &actorID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toStocktakeResponse(stocktake)
+	return &resp, nil
+}
+
+func (s *stocktakeService) Cancel(id uint) (*dto.StocktakeResponse, error) {
+	stocktake, err := s.stocktakes.Cancel(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toStocktakeResponse(stocktake)
+	return &resp, nil
+}
+
+// resolveCounts переводит введённые количества в счёт по строкам инвентаризации.
+// Общее количество фасовки или лекарства делится только между продаваемыми партиями:
+// отозванные и просроченные партии лежат отдельно до списания и считаются по batch_id,
+// иначе излишек мог бы попасть на отозванную серию, а недостача - на просроченную.
+// Счёт применяется в порядке передачи: поздний перекрывает ранний.
+func resolveCounts(lines []models.StocktakeLine, inputs []dto.StocktakeCountInput, now time.Time) (map[uint]int, error) {
+	lineByBatch := make(map[uint]*models.StocktakeLine, len(lines))
+	linesByVariant := make(map[uint][]*models.StocktakeLine)
+	linesByMedicine := make(map[uint][]*models.StocktakeLine)
+	for i := range lines {
+		line := &lines[i]
+		lineByBatch[line.BatchID] = line
+		if line.IsSellableAt(now) {
+			linesByVariant[line.VariantID] = append(linesByVariant[line.VariantID], line)
+			linesByMedicine[line.MedicineID] = append(linesByMedicine[line.MedicineID], line)
+		}
+	}
+
+	counts := make(map[uint]int)
+	for _, input := range inputs {
+		var pool []*models.StocktakeLine
+		switch {
+		case input.BatchID != nil && input.VariantID == nil && input.MedicineID == nil:
+			line, ok := lineByBatch[*input.BatchID]
+			if !ok {
+				return nil, errs.ErrInvalidStocktakeCount
+			}
+			counts[line.ID] = *input.CountedQuantity
+			continue
+		case input.VariantID != nil && input.BatchID == nil && input.MedicineID == nil:
+			pool = linesByVariant[*input.VariantID]
+		case input.MedicineID != nil && input.BatchID == nil && input.VariantID == nil:
+			pool = linesByMedicine[*input.MedicineID]
+			// фасовки одного лекарства - разные товары, общий счёт делится только внутри фасовки
+			for _, line := range pool {
+				if line.VariantID != pool[0].VariantID {
+					return nil, errs.ErrVariantRequired
+				}
+			}
+		}
+		if len(pool) == 0 {
+			return nil, errs.ErrInvalidStocktakeCount
+		}
+		for lineID, counted := range distributeCount(pool, *input.CountedQuantity) {
+			counts[lineID] = counted
+		}
+	}
+	return counts, nil
+}
+
+// distributeCount раскладывает подсчитанное количество фасовки по её продаваемым партиям.
+// Партии с дальним сроком заполняются первыми, так что недостача приходится на
+// ближайшие по сроку (они продаются первыми), а излишек - на самую позднюю.
+// Партии без срока (перенесённые остатки LEGACY) заполняются последними: излишек
+// на них не попадает, пока у фасовки есть партия со сроком.
+func distributeCount(lines []*models.StocktakeLine, counted int) map[uint]int {
+	ordered := slices.Clone(lines)
+	slices.SortFunc(ordered, func(a, b *models.StocktakeLine) int {
+		switch {
+		case a.ExpiresAt == nil && b.ExpiresAt == nil:
+			return cmp.Compare(b.ID, a.ID)
+		case a.ExpiresAt == nil:
+			return 1
+		case b.ExpiresAt == nil:
+			return -1
+		}
+		if c := b.ExpiresAt.Compare(*a.ExpiresAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	counts := make(map[uint]int, len(ordered))
+	remaining := counted
+	for _, line := range ordered {
+		take := min(line.ExpectedQuantity, remaining)
+		counts[line.ID] = take
+		remaining -= take
+	}
+	counts[ordered[0].ID] += remaining
+	return counts
+}
+
+func toStocktakeLineResponse(line models.StocktakeLine) dto.StocktakeLineResponse {
+	return dto.StocktakeLineResponse{
+		ID:               line.ID,
+		BatchID:          line.BatchID,
+		VariantID:        line.VariantID,
+		MedicineID:       line.MedicineID,
+		LotNumber:        line.LotNumber,
+		ExpiresAt:        line.ExpiresAt,
+		ExpectedQuantity: line.ExpectedQuantity,
+		CountedQuantity:  line.CountedQuantity,
+		Variance:         line.Variance(),
+		Adjustment:       line.Adjustment,
+		CountedAt:        line.CountedAt,
+	}
+}
+
+func toStocktakeResponse(stocktake *models.Stocktake) dto.StocktakeResponse {
+	resp := dto.StocktakeResponse{
+		ID:          stocktake.ID,
+		WarehouseID: stocktake.WarehouseID,
+		Status:      stocktake.Status,
+		Comment:     stocktake.Comment,
+		CreatedBy:   stocktake.CreatedBy,
+		AppliedBy:   stocktake.AppliedBy,
+		CreatedAt:   stocktake.CreatedAt,
+		AppliedAt:   stocktake.AppliedAt,
+	}
+	for _, line := range stocktake.Lines {
+		resp.Lines = append(resp.Lines, toStocktakeLineResponse(line))
+	}
+	return resp
+}
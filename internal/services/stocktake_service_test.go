@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+)
+
+func TestDistributeCount(t *testing.T) {
+	november := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		lines   []*models.StocktakeLine
+		counted int
+		want    map[uint]int
+	}{
+		{
+			name:    "exact count",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 5, ExpiresAt: &november}, {ID: 2, ExpectedQuantity: 5, ExpiresAt: &june}},
+			counted: 10,
+			want:    map[uint]int{1: 5, 2: 5},
+		},
+		{
+			name:    "shortage falls on the nearest expiry",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 5, ExpiresAt: &november}, {ID: 2, ExpectedQuantity: 5, ExpiresAt: &june}},
+			counted: 7,
+			want:    map[uint]int{1: 2, 2: 5},
+		},
+		{
+			name:    "surplus goes to the latest expiry",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 5, ExpiresAt: &november}, {ID: 2, ExpectedQuantity: 5, ExpiresAt: &june}},
+			counted: 12,
+			want:    map[uint]int{1: 5, 2: 7},
+		},
+		{
+			name:    "nothing found",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 5, ExpiresAt: &november}, {ID: 2, ExpectedQuantity: 5, ExpiresAt: &june}},
+			counted: 0,
+			want:    map[uint]int{1: 0, 2: 0},
+		},
+		{
+			name:    "surplus skips undated batches",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 3}, {ID: 2, ExpectedQuantity: 5, ExpiresAt: &november}},
+			counted: 10,
+			want:    map[uint]int{1: 3, 2: 7},
+		},
+		{
+			name:    "shortage falls on undated batches first",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 3}, {ID: 2, ExpectedQuantity: 5, ExpiresAt: &november}},
+			counted: 4,
+			want:    map[uint]int{1: 0, 2: 4},
+		},
+		{
+			name:    "only undated batches",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 3}, {ID: 2, ExpectedQuantity: 3}},
+			counted: 8,
+			want:    map[uint]int{1: 3, 2: 5},
+		},
+		{
+			name:    "empty batch found on the shelf",
+			lines:   []*models.StocktakeLine{{ID: 1, ExpectedQuantity: 0, ExpiresAt: &november}},
+			counted: 4,
+			want:    map[uint]int{1: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distributeCount(tt.lines, tt.counted)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("distributeCount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCounts(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	expired := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	november := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	recallID := uint(1)
+
+	// лекарство 1: фасовка 10 с двумя продаваемыми, просроченной и отозванной партиями;
+	// лекарство 2: две фасовки
+	lines := []models.StocktakeLine{
+		{ID: 1, BatchID: 101, VariantID: 10, MedicineID: 1, ExpectedQuantity: 5, ExpiresAt: &november},
+		{ID: 2, BatchID: 102, VariantID: 10, MedicineID: 1, ExpectedQuantity: 5, ExpiresAt: &june},
+		{ID: 3, BatchID: 103, VariantID: 10, MedicineID: 1, ExpectedQuantity: 4, ExpiresAt: &expired},
+		{ID: 4, BatchID: 104, VariantID: 10, MedicineID: 1, ExpectedQuantity: 4, ExpiresAt: &june, RecallID: &recallID},
+		{ID: 5, BatchID: 105, VariantID: 20, MedicineID: 2, ExpectedQuantity: 1, ExpiresAt: &june},
+		{ID: 6, BatchID: 106, VariantID: 21, MedicineID: 2, ExpectedQuantity: 1, ExpiresAt: &june},
+	}
+	batch := func(id uint, counted int) dto.StocktakeCountInput {
+		return dto.StocktakeCountInput{BatchID: &id, CountedQuantity: &counted}
+	}
+	variant := func(id uint, counted int) dto.StocktakeCountInput {
+		return dto.StocktakeCountInput{VariantID: &id, CountedQuantity: &counted}
+	}
+	medicine := func(id uint, counted int) dto.StocktakeCountInput {
+		return dto.StocktakeCountInput{MedicineID: &id, CountedQuantity: &counted}
+	}
+
+	tests := []struct {
+		name    string
+		inputs  []dto.StocktakeCountInput
+		want    map[uint]int
+		wantErr error
+	}{
+		{
+			name:   "variant count skips recalled and expired batches",
+			inputs: []dto.StocktakeCountInput{variant(10, 13)},
+			want:   map[uint]int{1: 5, 2: 8},
+		},
+		{
+			name:   "medicine with one variant",
+			inputs: []dto.StocktakeCountInput{medicine(1, 7)},
+			want:   map[uint]int{1: 2, 2: 5},
+		},
+		{
+			name:   "unsellable batches are counted by batch",
+			inputs: []dto.StocktakeCountInput{batch(103, 4), batch(104, 0)},
+			want:   map[uint]int{3: 4, 4: 0},
+		},
+		{
+			name:   "later count overrides earlier",
+			inputs: []dto.StocktakeCountInput{variant(10, 10), batch(101, 3)},
+			want:   map[uint]int{1: 3, 2: 5},
+		},
+		{
+			name:    "medicine with several variants",
+			inputs:  []dto.StocktakeCountInput{medicine(2, 2)},
+			wantErr: errs.ErrVariantRequired,
+		},
+		{
+			name:    "unknown variant",
+			inputs:  []dto.StocktakeCountInput{variant(99, 1)},
+			wantErr: errs.ErrInvalidStocktakeCount,
+		},
+		{
+			name: "batch and variant together",
+			inputs: []dto.StocktakeCountInput{{
+				BatchID: new(uint), VariantID: new(uint), CountedQuantity: new(int),
+			}},
+			wantErr: errs.ErrInvalidStocktakeCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveCounts(lines, tt.inputs, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveCounts() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !maps.Equal(got, tt.want) {
+				t.Errorf("resolveCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ type UserService interface {
 	UpdateUser(id uint, req dto.UpdateUserRequest) (*dto.CreateUserResponse, error)
 	DeleteUser(id uint) error
 	ListUsers() ([]dto.CreateUserResponse, error)
+	UpdateRole(id uint, req dto.UpdateUserRoleRequest) (*dto.CreateUserResponse, error)
 }
 
 type userService struct {
@@ -45,6 +46,7 @@ func (s *userService) CreateUser(req dto.CreateUserRequest) (*dto.CreateUserResp
 		Email:          user.Email,
 		Phone:          user.Phone,
 		DefaultAddress: user.DefaultAddress,
+		Role:           user.Role,
 	}, nil
 }
 
@@ -65,6 +67,7 @@ func (s *userService) GetUserByID(id uint) (*dto.CreateUserResponse, error) {
 		Email:          user.Email,
 		Phone:          user.Phone,
 		DefaultAddress: user.DefaultAddress,
+		Role:           user.Role,
 	}, nil
 }
 
@@ -92,6 +95,7 @@ func (s *userService) UpdateUser(id uint, req dto.UpdateUserRequest) (*dto.Creat
 		Email:          user.Email,
 		Phone:          user.Phone,
 		DefaultAddress: user.DefaultAddress,
+		Role:           user.Role,
 	}, nil
 }
 
@@ -124,12 +128,41 @@ func (s *userService) ListUsers() ([]dto.CreateUserResponse, error) {
 			Email:          user.Email,
 			Phone:          user.Phone,
 			DefaultAddress: user.DefaultAddress,
+			Role:           user.Role,
 		})
 
 	}
 	return usersResp, nil
 }
 
+func (s *userService) UpdateRole(id uint, req dto.UpdateUserRoleRequest) (*dto.CreateUserResponse, error) {
+	if id == 0 {
+		return nil, errors.New("invalid id")
+	}
+	if !req.Role.IsValid() {
+		return nil, errors.New("неизвестная роль")
+	}
+	user, err := s.users.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user.Role = req.Role
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+	return &dto.CreateUserResponse{
+		FullName:       user.FullName,
+		Email:          user.Email,
+		Phone:          user.Phone,
+		DefaultAddress: user.DefaultAddress,
+		Role:           user.Role,
+	}, nil
+}
+
 func (s *userService) applyUserUpdate(user *models.User, req dto.UpdateUserRequest) error {
 
 	if req.FullName != nil {
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type WarehouseService interface {
+	Create(req dto.WarehouseCreate) (*models.Warehouse, error)
+	Update(id uint, req dto.WarehouseUpdate) (*models.Warehouse, error)
+	List(includeInactive bool) ([]models.Warehouse, error)
+	Stock(warehouseID uint) ([]dto.WarehouseStockResponse, error)
+	MedicineAvailability(medicineID uint) ([]dto.BranchAvailabilityResponse, error)
+	Transfer(actorID uint, req dto.StockTransferRequest) (*dto.StockTransferResponse, error)
+	ListTransfers(warehouseID *uint) ([]dto.StockTransferResponse, error)
+	// PickFulfilment выбирает филиал, из которого будет собран заказ целиком:
+	// выбранный покупателем для самовывоза либо подходящий по адресу доставки.
+	PickFulfilment(address string, pickupWarehouseID *uint, items []models.OrderItem) (*models.Warehouse, error)
+}
+
+type warehouseService struct {
+	warehouses repository.WarehouseRepository
+	medicines  repository.MedicineRepository
+}
+
+func NewWarehouseService(warehouses repository.WarehouseRepository, medicines repository.MedicineRepository) WarehouseService {
+	return &warehouseService{warehouses: warehouses, medicines: medicines}
+}
+
+func (s *warehouseService) Create(req dto.WarehouseCreate) (*models.Warehouse, error) {
+	warehouse := &models.Warehouse{
+		Code:     strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:     strings.TrimSpace(req.Name),
+		City:     strings.TrimSpace(req.City),
+		Address:  strings.TrimSpace(req.Address),
+		Priority: req.Priority,
+		IsActive: true,
+	}
+	if err := s.warehouses.Create(warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (s *warehouseService) Update(id uint, req dto.WarehouseUpdate) (*models.Warehouse, error) {
+	warehouse, err := s.warehouses.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		warehouse.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.City != nil {
+		warehouse.City = strings.TrimSpace(*req.City)
+	}
+	if req.Address != nil {
+		warehouse.Address = strings.TrimSpace(*req.Address)
+	}
+	if req.Priority != nil {
+		warehouse.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+
+	if err := s.warehouses.Update(warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (s *warehouseService) List(includeInactive bool) ([]models.Warehouse, error) {
+	return s.warehouses.List(!includeInactive)
+}
+
+func (s *warehouseService) Stock(warehouseID uint) ([]dto.WarehouseStockResponse, error) {
+	if _, err := s.warehouses.GetByID(warehouseID); err != nil {
+		return nil, err
+	}
+
+	levels, err := s.warehouses.WarehouseStockLevels(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.WarehouseStockResponse, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, dto.WarehouseStockResponse{VariantID: level.VariantID, Quantity: level.Quantity})
+	}
+	return result, nil
+}
+
+func (s *warehouseService) MedicineAvailability(medicineID uint) ([]dto.BranchAvailabilityResponse, error) {
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrMedicineNotFound
+		}
+		return nil, err
+	}
+
+	warehouses, err := s.warehouses.List(true)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := s.warehouses.MedicineStock(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	byWarehouse := make(map[uint][]dto.WarehouseStockResponse, len(warehouses))
+	for _, level := range levels {
+		byWarehouse[level.WarehouseID] = append(byWarehouse[level.WarehouseID],
+			dto.WarehouseStockResponse{VariantID: level.VariantID, Quantity: level.Quantity})
+	}
+
+	result := make([]dto.BranchAvailabilityResponse, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		variants := byWarehouse[warehouse.ID]
+		if variants == nil {
+			variants = []dto.WarehouseStockResponse{}
+		}
+		result = append(result, dto.BranchAvailabilityResponse{
+			WarehouseID: warehouse.ID,
+			Code:        warehouse.Code,
+			Name:        warehouse.Name,
+			City:        warehouse.City,
+			Address:     warehouse.Address,
+			InStock:     len(variants) > 0,
+			Variants:    variants,
+		})
+	}
+	return result, nil
+}
+
+func (s *warehouseService) Transfer(actorID uint, req dto.StockTransferRequest) (*dto.StockTransferResponse, error) {
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return nil, errs.ErrInvalidTransfer
+	}
+	for _, id := range []uint{req.FromWarehouseID, req.ToWarehouseID} {
+		warehouse, err := s.warehouses.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if !warehouse.IsActive {
+			return nil, errs.ErrInvalidTransfer
+		}
+	}
+
+	transfer := &models.StockTransfer{
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		VariantID:       req.VariantID,
+		Quantity:        req.Quantity,
+		Comment:         strings.TrimSpace(req.Comment),
+		CreatedBy:       &actorID,
+	}
+	if err := s.warehouses.Transfer(transfer); err != nil {
+		return nil, err
+	}
+
+	resp := toStockTransferResponse(transfer)
+	return &resp, nil
+}
+
+func (s *warehouseService) ListTransfers(warehouseID *uint) ([]dto.StockTransferResponse, error) {
+	transfers, err := s.warehouses.ListTransfers(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.StockTransferResponse, 0, len(transfers))
+	for i := range transfers {
+		result = append(result, toStockTransferResponse(&transfers[i]))
+	}
+	return result, nil
+}
+
+func (s *warehouseService) PickFulfilment(address string, pickupWarehouseID *uint, items []models.OrderItem) (*models.Warehouse, error) {
+	required := make(map[uint]uint, len(items))
+	variantIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		if item.VariantID == nil {
+			return nil, errs.ErrVariantRequired
+		}
+		if _, ok := required[*item.VariantID]; !ok {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+		required[*item.VariantID] += uint(item.Quantity)
+	}
+
+	levels, err := s.warehouses.Stock(variantIDs)
+	if err != nil {
+		return nil, err
+	}
+	stock := make(map[uint]map[uint]uint)
+	for _, level := range levels {
+		if stock[level.WarehouseID] == nil {
+			stock[level.WarehouseID] = make(map[uint]uint)
+		}
+		stock[level.WarehouseID][level.VariantID] = level.Quantity
+	}
+	canFulfil := func(warehouseID uint) bool {
+		for variantID, quantity := range required {
+			if stock[warehouseID][variantID] < quantity {
+				return false
+			}
+		}
+		return true
+	}
+
+	if pickupWarehouseID != nil {
+		warehouse, err := s.warehouses.GetByID(*pickupWarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if !warehouse.IsActive {
+			return nil, errs.ErrWarehouseNotFound
+		}
+		if !canFulfil(warehouse.ID) {
+			return nil, fmt.Errorf("%w: %s", errs.ErrInsufficientStock, warehouse.Name)
+		}
+		return warehouse, nil
+	}
+
+	warehouses, err := s.warehouses.List(true)
+	if err != nil {
+		return nil, err
+	}
+
+	// сначала филиалы из города доставки, затем остальные; внутри - по приоритету
+	address = strings.ToLower(address)
+	var fallback *models.Warehouse
+	for i := range warehouses {
+		warehouse := &warehouses[i]
+		if !canFulfil(warehouse.ID) {
+			continue
+		}
+		city := strings.ToLower(warehouse.City)
+		if city != "" && strings.Contains(address, city) {
+			return warehouse, nil
+		}
+		if fallback == nil {
+			fallback = warehouse
+		}
+	}
+	if fallback == nil {
+		return nil, errs.ErrNoFulfilmentWarehouse
+	}
+	return fallback, nil
+}
+
+func toStockTransferResponse(transfer *models.StockTransfer) dto.StockTransferResponse {
+	lines := make([]dto.StockTransferLineResponse, 0, len(transfer.Lines))
+	for _, line := range transfer.Lines {
+		lines = append(lines, dto.StockTransferLineResponse{
+			LotNumber:   line.LotNumber,
+			ExpiresAt:   line.ExpiresAt,
+			Quantity:    line.Quantity,
+			FromBatchID: line.FromBatchID,
+			ToBatchID:   line.ToBatchID,
+		})
+	}
+	return dto.StockTransferResponse{
+		ID:              transfer.ID,
+		FromWarehouseID: transfer.FromWarehouseID,
+		ToWarehouseID:   transfer.ToWarehouseID,
+		VariantID:       transfer.VariantID,
+		MedicineID:      transfer.MedicineID,
+		Quantity:        transfer.Quantity,
+		Comment:         transfer.Comment,
+		CreatedBy:       transfer.CreatedBy,
+		CreatedAt:       transfer.CreatedAt,
+		Lines:           lines,
+	}
+}
@@ -21,13 +21,14 @@ func NewCartHandler(logger *slog.Logger, service services.CartService) *CartHand
 	return &CartHandler{service: service, logger: logger.With("layer", "transport", "entity", "cart")}
 }
 
-func (h *CartHandler) RegisterRoutes(r *gin.Engine) {
-	cart := r.Group("/users/:id/cart")
+func (h *CartHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	cart := r.Group("/users/:id/cart", auth, RequireSelf())
 	{
 		cart.GET("", h.GetCart)
 		cart.POST("/items", h.CreateItem)
 		cart.PATCH("/items/:item_id", h.UpdateItem)
 		cart.DELETE("/items/:item_id", h.DeleteItem)
+		cart.POST("/items/:item_id/swap", h.SwapItem)
 		cart.DELETE("", h.ClearCart)
 
 	}
@@ -35,17 +36,11 @@ func (h *CartHandler) RegisterRoutes(r *gin.Engine) {
 
 func (h *CartHandler) GetCart(c *gin.Context) {
 
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-
-	if err != nil {
-		h.logger.Warn("invalid user ID", "param", c.Param("id"), "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	userID := subjectUserID(c)
 
 	h.logger.Info("incoming request", "method", c.Request.Method, "user_id", userID)
 
-	cart, err := h.service.GetCartWithItems(uint(userID))
+	cart, err := h.service.GetCartWithItems(userID)
 	if err != nil {
 		if errors.Is(err, errs.ErrUserNotFound) {
 
@@ -67,16 +62,7 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 }
 
 func (h *CartHandler) CreateItem(c *gin.Context) {
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid  userID",
-			"param", c.Param("id"),
-			"error", err,
-		)
-
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	userID := subjectUserID(c)
 	var req *dto.AddCartItemRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -95,7 +81,7 @@ func (h *CartHandler) CreateItem(c *gin.Context) {
 		"quantity", req.Quantity,
 	)
 
-	cart, err := h.service.CreateItem(uint(userID), req)
+	cart, err := h.service.CreateItem(userID, req)
 	if err != nil {
 		if errors.Is(err, errs.ErrUserNotFound) {
 
@@ -104,6 +90,18 @@ func (h *CartHandler) CreateItem(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, errs.ErrPrescriptionRequired) {
+			h.logger.Warn("prescription required",
+				"user_id", userID,
+				"medicine_id", req.MedicineID,
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrVariantRequired) || errors.Is(err, errs.ErrVariantNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to add item to cart",
 			"user_id", userID,
 			"medicine_id", req.MedicineID,
@@ -124,17 +122,7 @@ func (h *CartHandler) CreateItem(c *gin.Context) {
 }
 
 func (h *CartHandler) UpdateItem(c *gin.Context) {
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-
-		h.logger.Warn("invalid  userID",
-			"raw_value", c.Param("id"),
-			"error", err,
-		)
-
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
-		return
-	}
+	userID := subjectUserID(c)
 
 	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 64)
 	if err != nil {
@@ -162,7 +150,7 @@ func (h *CartHandler) UpdateItem(c *gin.Context) {
 		"item_id", itemID,
 	)
 
-	newItem, err := h.service.UpdateItem(uint(userID), uint(itemID), req)
+	newItem, err := h.service.UpdateItem(userID, uint(itemID), req)
 
 	if err != nil {
 		if errors.Is(err, errs.ErrUserNotFound) {
@@ -192,19 +180,69 @@ func (h *CartHandler) UpdateItem(c *gin.Context) {
 	c.JSON(http.StatusOK, newItem)
 }
 
-func (h *CartHandler) DeleteItem(c *gin.Context) {
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
+func (h *CartHandler) SwapItem(c *gin.Context) {
+	userID := subjectUserID(c)
 
-		h.logger.Warn("invalid userID",
-			"raw_value", c.Param("id"),
-			"error", err,
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid itemID",
+			"raw_value", c.Param("item_id"),
+			"user_id", userID, "error", err,
 		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
 
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+	var req dto.SwapCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body", "user_id", userID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("incoming request",
+		"method", c.Request.Method,
+		"path", c.FullPath(),
+		"user_id", userID,
+		"item_id", itemID,
+		"medicine_id", req.MedicineID,
+	)
+
+	cart, err := h.service.SwapItem(userID, uint(itemID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCartNotFound), errors.Is(err, errs.ErrItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errs.ErrNotAnAnalog), errors.Is(err, errs.ErrVariantRequired),
+			errors.Is(err, errs.ErrVariantNotFound):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, errs.ErrPrescriptionRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, errs.ErrInsufficientStock):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to swap cart item",
+				"user_id", userID,
+				"item_id", itemID,
+				"error", err,
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
+	h.logger.Info("cart item swapped",
+		"user_id", userID,
+		"item_id", itemID,
+		"medicine_id", req.MedicineID,
+	)
+
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *CartHandler) DeleteItem(c *gin.Context) {
+	userID := subjectUserID(c)
+
 	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 64)
 	if err != nil {
 
@@ -221,7 +259,7 @@ func (h *CartHandler) DeleteItem(c *gin.Context) {
 		"user_id", userID,
 		"item_id", itemID,
 	)
-	if err := h.service.DeleteItem(uint(userID), uint(itemID)); err != nil {
+	if err := h.service.DeleteItem(userID, uint(itemID)); err != nil {
 		if errors.Is(err, errs.ErrUserNotFound) || errors.Is(err, errs.ErrItemNotFound) {
 			h.logger.Warn("resource not found",
 				"user_id", userID,
@@ -249,21 +287,13 @@ func (h *CartHandler) DeleteItem(c *gin.Context) {
 }
 
 func (h *CartHandler) ClearCart(c *gin.Context) {
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid userID",
-			"raw_value", c.Param("id"),
-			"error", err,
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	userID := subjectUserID(c)
 
 	h.logger.Info("incoming request",
 		"user_id", userID,
 	)
 
-	if err := h.service.ClearCart(uint(userID)); err != nil {
+	if err := h.service.ClearCart(userID); err != nil {
 		if errors.Is(err, errs.ErrUserNotFound) {
 			h.logger.Warn("user not found",
 				"user_id", userID)
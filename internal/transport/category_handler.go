@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -17,12 +18,12 @@ func NewCategoryHandler(service services.CategoryService) *CategoryHandler {
 	return &CategoryHandler{service: service}
 }
 
-func (h *CategoryHandler) RegisterRoutes(r *gin.Engine) {
+func (h *CategoryHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
 	categories := r.Group("/categories")
 
 	{
 		categories.GET("/:id", h.GetByID)
-		categories.POST("", h.Create)
+		categories.POST("", auth, RequireRole(models.RoleAdmin), h.Create)
 		categories.GET("", h.GetList)
 	}
 }
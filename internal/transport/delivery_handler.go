@@ -0,0 +1,177 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeliveryHandler struct {
+	service services.DeliveryService
+}
+
+func NewDeliveryHandler(service services.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{service: service}
+}
+
+func (h *DeliveryHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	r.GET("/delivery-zones", h.ListZones)
+	r.GET("/delivery-zones/quote", h.Quote)
+	r.GET("/delivery-zones/:id/slots", h.AvailableSlots)
+
+	admin := RequireRole(models.RoleAdmin)
+
+	zones := r.Group("/delivery-zones", auth)
+	{
+		zones.POST("", admin, h.CreateZone)
+		zones.PATCH("/:id", admin, h.UpdateZone)
+		zones.POST("/:id/slots", admin, h.CreateSlots)
+	}
+
+	slots := r.Group("/delivery-slots", auth)
+	{
+		slots.PATCH("/:id", admin, h.UpdateSlot)
+	}
+}
+
+func (h *DeliveryHandler) CreateZone(c *gin.Context) {
+	var req dto.DeliveryZoneCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	zone, err := h.service.CreateZone(req)
+	if err != nil {
+		writeDeliveryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, zone)
+}
+
+func (h *DeliveryHandler) UpdateZone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.DeliveryZoneUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	zone, err := h.service.UpdateZone(uint(id), req)
+	if err != nil {
+		writeDeliveryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+func (h *DeliveryHandler) ListZones(c *gin.Context) {
+	zones, err := h.service.ListZones(c.Query("include_inactive") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+func (h *DeliveryHandler) Quote(c *gin.Context) {
+	var query dto.DeliveryQuoteQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.service.Quote(query)
+	if err != nil {
+		writeDeliveryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *DeliveryHandler) CreateSlots(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.DeliverySlotsCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slots, err := h.service.CreateSlots(uint(id), req)
+	if err != nil {
+		writeDeliveryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, slots)
+}
+
+func (h *DeliveryHandler) UpdateSlot(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.DeliverySlotUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slot, err := h.service.UpdateSlot(uint(id), req)
+	if err != nil {
+		writeDeliveryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, slot)
+}
+
+func (h *DeliveryHandler) AvailableSlots(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var query dto.DeliverySlotQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slots, err := h.service.AvailableSlots(uint(id), query)
+	if err != nil {
+		writeDeliveryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+func writeDeliveryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrDeliveryZoneNotFound), errors.Is(err, errs.ErrDeliverySlotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidDeliveryZone), errors.Is(err, errs.ErrOutsideDeliveryZone),
+		errors.Is(err, errs.ErrDeliveryPointMismatch),
+		errors.Is(err, errs.ErrInvalidDeliverySlot):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
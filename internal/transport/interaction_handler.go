@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InteractionHandler struct {
+	service services.InteractionService
+}
+
+func NewInteractionHandler(service services.InteractionService) *InteractionHandler {
+	return &InteractionHandler{service: service}
+}
+
+func (h *InteractionHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	interactions := r.Group("/interactions", auth)
+	{
+		interactions.GET("", RequireRole(models.RolePharmacist, models.RoleAdmin), h.List)
+		interactions.POST("/import", RequireRole(models.RoleAdmin), h.Import)
+	}
+	r.GET("/users/:id/cart/interactions", auth, RequireSelf(), h.CheckCart)
+}
+
+func (h *InteractionHandler) Import(c *gin.Context) {
+	var req dto.InteractionImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Import(req)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidInteraction) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *InteractionHandler) List(c *gin.Context) {
+	interactions, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, interactions)
+}
+
+func (h *InteractionHandler) CheckCart(c *gin.Context) {
+	result, err := h.service.CheckCart(subjectUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"net/http"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	service services.InventoryService
+}
+
+func NewInventoryHandler(service services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{service: service}
+}
+
+func (h *InventoryHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	inventory := r.Group("/inventory", auth, RequireRole(models.RolePharmacist, models.RoleAdmin))
+	{
+		inventory.GET("/low-stock", h.LowStock)
+	}
+}
+
+func (h *InventoryHandler) LowStock(c *gin.Context) {
+	report, err := h.service.LowStockReport()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -1,10 +1,13 @@
 package transport
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
 	"team-pharmacy/internal/logger"
+	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -17,14 +20,23 @@ type MedicineHandler struct {
 func NewMedicineHandler(service services.MedicineService) *MedicineHandler {
 	return &MedicineHandler{service: service}
 }
-func (m *MedicineHandler) RegisterRoutes(r *gin.Engine) {
+func (m *MedicineHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
 	medicines := r.Group("/medicines")
 	{
 		medicines.GET("", m.GetAll)
-		medicines.POST("", m.Create)
+		medicines.GET("/search", m.Search)
 		medicines.GET("/:id", m.GetByID)
-		medicines.PATCH("/:id", m.Update)
-		medicines.DELETE("/:id", m.Delete)
+		medicines.GET("/:id/analogs", m.Analogs)
+		medicines.GET("/:id/variants", m.ListVariants)
+	}
+	admin := r.Group("/medicines", auth, RequireRole(models.RoleAdmin))
+	{
+		admin.POST("", m.Create)
+		admin.PATCH("/:id", m.Update)
+		admin.DELETE("/:id", m.Delete)
+		admin.POST("/:id/variants", m.CreateVariant)
+		admin.PATCH("/:id/variants/:variant_id", m.UpdateVariant)
+		admin.DELETE("/:id/variants/:variant_id", m.DeleteVariant)
 	}
 }
 func (m *MedicineHandler) Create(ctx *gin.Context) {
@@ -53,15 +65,45 @@ func (m *MedicineHandler) Create(ctx *gin.Context) {
 }
 
 func (m *MedicineHandler) GetAll(ctx *gin.Context) {
-	medicines, err := m.service.GetAll()
-	if err != nil {
-		
+	var query dto.MedicineListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+
+	medicines, err := m.service.List(query)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidCatalogQuery) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Error("Handler:List medicines error", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
 	ctx.JSON(http.StatusOK, medicines)
 }
 
+func (m *MedicineHandler) Search(ctx *gin.Context) {
+	var query dto.MedicineSearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := m.service.Search(query)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidCatalogQuery) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Error("Handler:Search medicines error", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
 func (m *MedicineHandler) GetByID(ctx *gin.Context) {
 	id_check := ctx.Param("id")
 	id, err := strconv.Atoi(id_check)
@@ -81,6 +123,26 @@ func (m *MedicineHandler) GetByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, medicine)
 }
 
+func (m *MedicineHandler) Analogs(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	analogs, err := m.service.Analogs(uint(id))
+	if err != nil {
+		if errors.Is(err, errs.ErrMedicineNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Error("Handler:Analogs medicine error", "medicine_id", id, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, analogs)
+}
+
 func (m *MedicineHandler) Update(ctx *gin.Context) {
 	id_check := ctx.Param("id")
 	id, err := strconv.Atoi(id_check)
@@ -117,3 +179,95 @@ func (m *MedicineHandler) Delete(ctx *gin.Context) {
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+func (m *MedicineHandler) ListVariants(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	variants, err := m.service.ListVariants(uint(id))
+	if err != nil {
+		m.writeVariantError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, variants)
+}
+
+func (m *MedicineHandler) CreateVariant(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.MedicineVariantInput
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := m.service.CreateVariant(uint(id), req)
+	if err != nil {
+		m.writeVariantError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, variant)
+}
+
+func (m *MedicineHandler) UpdateVariant(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+	variantID, err := strconv.ParseUint(ctx.Param("variant_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct variant id"})
+		return
+	}
+
+	var req dto.MedicineVariantUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := m.service.UpdateVariant(uint(id), uint(variantID), req)
+	if err != nil {
+		m.writeVariantError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, variant)
+}
+
+func (m *MedicineHandler) DeleteVariant(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+	variantID, err := strconv.ParseUint(ctx.Param("variant_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct variant id"})
+		return
+	}
+
+	if err := m.service.DeleteVariant(uint(id), uint(variantID)); err != nil {
+		m.writeVariantError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (m *MedicineHandler) writeVariantError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrMedicineNotFound), errors.Is(err, errs.ErrVariantNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrLastVariant), errors.Is(err, errs.ErrVariantHasStock):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ctxUserIDKey        = "user_id"
+	ctxUserRoleKey      = "user_role"
+	ctxSubjectUserIDKey = "subject_user_id"
+)
+
+// RequireAuth проверяет access-токен из заголовка Authorization и кладёт
+// id и роль текущего пользователя в контекст запроса.
+func RequireAuth(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errs.ErrUnauthorized.Error()})
+			return
+		}
+
+		claims, err := authService.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(ctxUserIDKey, claims.UserID)
+		c.Set(ctxUserRoleKey, models.Role(claims.Role))
+		c.Next()
+	}
+}
+
+// RequireRole пропускает запрос, только если роль текущего пользователя входит
+// в список разрешённых. Ставится после RequireAuth.
+func RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := currentUserRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errs.ErrForbidden.Error()})
+	}
+}
+
+// RequireSelf пропускает запросы к /users/:id/... только для владельца ресурса
+// или администратора. Обработчики берут id пользователя через subjectUserID, а не из пути.
+func RequireSelf() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pathUserID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+
+		if uint(pathUserID) != currentUserID(c) && currentUserRole(c) != models.RoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errs.ErrForbidden.Error()})
+			return
+		}
+
+		c.Set(ctxSubjectUserIDKey, uint(pathUserID))
+		c.Next()
+	}
+}
+
+// RequireOrderAccess пропускает запросы к /orders/:id/... только для владельца
+// заказа или сотрудника аптеки.
+func RequireOrderAccess(orderService services.OrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+			return
+		}
+
+		order, err := orderService.GetByID(uint(orderID))
+		if err != nil {
+			if errors.Is(err, errs.ErrOrderNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "order not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+
+		if order.UserID != currentUserID(c) && !currentUserRole(c).IsStaff() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errs.ErrForbidden.Error()})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func currentUserID(c *gin.Context) uint {
+	return c.GetUint(ctxUserIDKey)
+}
+
+func currentUserRole(c *gin.Context) models.Role {
+	role, _ := c.Get(ctxUserRoleKey)
+	r, _ := role.(models.Role)
+	return r
+}
+
+func subjectUserID(c *gin.Context) uint {
+	return c.GetUint(ctxSubjectUserIDKey)
+}
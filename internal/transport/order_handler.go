@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"team-pharmacy/internal/dto"
 	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -21,14 +22,17 @@ func NewOrderHandler(orderService services.OrderService, userService services.Us
 	return &OrderHandler{orderService: orderService, userService: userService, cartService: cartService}
 }
 
-func (h *OrderHandler) RegisterRoutes(r *gin.Engine) {
-	order := r.Group("/orders/:id")
+func (h *OrderHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	order := r.Group("/orders/:id", auth, RequireOrderAccess(h.orderService))
 	{
 		order.GET("", h.GetOrder)
 		order.PATCH("/status", h.UpdateStatus)
+		order.POST("/cancel", h.CancelOrder)
+		order.GET("/history", h.GetHistory)
+		order.POST("/pickup", RequireRole(models.RolePharmacist, models.RoleAdmin), h.CompletePickup)
 
 	}
-	user := r.Group("/users/:id")
+	user := r.Group("/users/:id", auth, RequireSelf())
 	{
 		user.POST("/orders", h.CreateOrder)
 		user.GET("/orders", h.GetAllOrdersUser)
@@ -38,11 +42,7 @@ func (h *OrderHandler) RegisterRoutes(r *gin.Engine) {
 
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	userID := subjectUserID(c)
 
 	var req dto.OrderCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -50,12 +50,46 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.CreateOrder(uint(userID), &req)
+	order, err := h.orderService.CreateOrder(userID, &req)
 	if err != nil {
 		if errors.Is(err, errs.ErrCartNotFound) || errors.Is(err, errs.ErrCartIsEmpty) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "cart not found or is empty"})
 			return
 		}
+		if errors.Is(err, errs.ErrPromocodeNotFound) ||
+			errors.Is(err, errs.ErrPromocodeInactive) ||
+			errors.Is(err, errs.ErrPromocodeNotApplicable) ||
+			errors.Is(err, errs.ErrPromocodeUsageLimit) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrPrescriptionRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrOutsideDeliveryZone) || errors.Is(err, errs.ErrBelowMinimumOrder) ||
+			errors.Is(err, errs.ErrDeliverySlotNotFound) || errors.Is(err, errs.ErrDeliveryPointMismatch) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrSevereInteraction) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrWarehouseNotFound) || errors.Is(err, errs.ErrPickupPointNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrDeliveryAddressRequired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrInsufficientStock) || errors.Is(err, errs.ErrMedicineNotFound) ||
+			errors.Is(err, errs.ErrVariantNotFound) || errors.Is(err, errs.ErrNoFulfilmentWarehouse) ||
+			errors.Is(err, errs.ErrDeliverySlotUnavailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
@@ -78,18 +112,15 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
+	hidePickupCode(c, order)
 	c.JSON(http.StatusOK, order)
 }
 
 func (h *OrderHandler) GetAllOrdersUser(c *gin.Context) {
 
-	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	userID := subjectUserID(c)
 
-	orders, err := h.orderService.GetListOrders(uint(userID))
+	orders, err := h.orderService.GetListOrders(userID)
 	if err != nil {
 		if errors.Is(err, errs.ErrOrderNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "orders not found"})
@@ -114,13 +145,117 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.orderService.UpdateOrder(uint(orderID), &req); err != nil {
-		if errors.Is(err, errs.ErrInvalidStatus) {
+	if err := h.orderService.UpdateOrder(uint(orderID), currentUserID(c), currentUserRole(c), &req); err != nil {
+		if errors.Is(err, errs.ErrInvalidStatus) || errors.Is(err, errs.ErrInvalidStatusTransition) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "status error"})
 			return
 		}
+		if errors.Is(err, errs.ErrOrderRecalled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 	c.Status(http.StatusOK)
 }
+
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.OrderCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(uint(orderID), currentUserID(c), &req)
+	if err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		if errors.Is(err, errs.ErrOrderNotCancelable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	hidePickupCode(c, order)
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) CompletePickup(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.OrderPickupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.orderService.CompletePickup(uint(orderID), currentUserID(c), &req)
+	if err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		if errors.Is(err, errs.ErrOrderNotReadyForPickup) || errors.Is(err, errs.ErrOrderRecalled) ||
+			errors.Is(err, errs.ErrPickupPointClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrInvalidPickupCode) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	hidePickupCode(c, order)
+	c.JSON(http.StatusOK, order)
+}
+
+// hidePickupCode убирает код получения из ответа всем, кроме покупателя: иначе
+// сотрудник пункта мог бы выдать заказ, не спрашивая код.
+func hidePickupCode(c *gin.Context, order *dto.OrderResponse) {
+	if order.UserID != currentUserID(c) {
+		order.PickupCode = ""
+	}
+}
+
+func (h *OrderHandler) GetHistory(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.orderService.GetHistory(uint(orderID))
+	if err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
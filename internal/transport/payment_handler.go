@@ -0,0 +1,136 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	service services.PaymentService
+}
+
+func NewPaymentHandler(service services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+func (h *PaymentHandler) RegisterRoutes(r *gin.Engine, auth, orderAccess gin.HandlerFunc) {
+	payments := r.Group("/orders/:id/payments", auth, orderAccess)
+	{
+		payments.POST("", h.StartPayment)
+		payments.GET("", h.ListByOrder)
+
+		// подтверждение и отказ приходят от шлюза, а не от покупателя:
+		// иначе владелец заказа мог бы сам отметить его оплаченным
+		provider := payments.Group("/:payment_id", RequireRole(models.RoleAdmin))
+		{
+			provider.POST("/confirm", h.ConfirmPayment)
+			provider.POST("/fail", h.FailPayment)
+		}
+	}
+}
+
+func (h *PaymentHandler) StartPayment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	var req dto.PaymentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := h.service.StartPayment(uint(orderID), &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, payment)
+}
+
+func (h *PaymentHandler) ListByOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	payments, err := h.service.ListByOrder(uint(orderID))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) ConfirmPayment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	paymentID, err := strconv.ParseUint(c.Param("payment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return
+	}
+
+	payment, err := h.service.ConfirmPayment(uint(orderID), uint(paymentID), currentUserID(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) FailPayment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	paymentID, err := strconv.ParseUint(c.Param("payment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return
+	}
+
+	var req dto.PaymentFailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := h.service.FailPayment(uint(orderID), uint(paymentID), &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrOrderNotFound), errors.Is(err, errs.ErrPaymentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrOrderNotPayable),
+		errors.Is(err, errs.ErrPaymentAlreadyProcessed),
+		errors.Is(err, errs.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
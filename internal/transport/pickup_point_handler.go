@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PickupPointHandler struct {
+	service services.PickupPointService
+}
+
+func NewPickupPointHandler(service services.PickupPointService) *PickupPointHandler {
+	return &PickupPointHandler{service: service}
+}
+
+func (h *PickupPointHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	r.GET("/pickup-points", h.List)
+	r.GET("/pickup-points/:id", h.Get)
+
+	admin := RequireRole(models.RoleAdmin)
+
+	points := r.Group("/pickup-points", auth)
+	{
+		points.POST("", admin, h.Create)
+		points.PATCH("/:id", admin, h.Update)
+	}
+}
+
+func (h *PickupPointHandler) Create(c *gin.Context) {
+	var req dto.PickupPointCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	point, err := h.service.Create(req)
+	if err != nil {
+		writePickupPointError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, point)
+}
+
+func (h *PickupPointHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.PickupPointUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	point, err := h.service.Update(uint(id), req)
+	if err != nil {
+		writePickupPointError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, point)
+}
+
+func (h *PickupPointHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	point, err := h.service.GetByID(uint(id))
+	if err != nil {
+		writePickupPointError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, point)
+}
+
+func (h *PickupPointHandler) List(c *gin.Context) {
+	var query dto.PickupPointQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	points, err := h.service.List(query)
+	if err != nil {
+		writePickupPointError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, points)
+}
+
+func writePickupPointError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrPickupPointNotFound), errors.Is(err, errs.ErrWarehouseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidOpeningHours):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
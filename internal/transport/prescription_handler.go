@@ -0,0 +1,208 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxPrescriptionFileSize = 10 << 20
+
+var allowedPrescriptionExtensions = map[string]bool{
+	".pdf":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+type PrescriptionHandler struct {
+	service   services.PrescriptionService
+	uploadDir string
+}
+
+func NewPrescriptionHandler(service services.PrescriptionService, uploadDir string) *PrescriptionHandler {
+	return &PrescriptionHandler{service: service, uploadDir: uploadDir}
+}
+
+func (h *PrescriptionHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	user := r.Group("/users/:id/prescriptions", auth, RequireSelf())
+	{
+		user.POST("", h.Upload)
+		user.GET("", h.ListByUser)
+	}
+	prescriptions := r.Group("/prescriptions", auth, RequireRole(models.RolePharmacist, models.RoleAdmin))
+	{
+		prescriptions.GET("", h.ListQueue)
+		prescriptions.GET("/:id", h.GetByID)
+		prescriptions.GET("/:id/file", h.DownloadFile)
+		prescriptions.POST("/:id/approve", h.Approve)
+		prescriptions.POST("/:id/reject", h.Reject)
+	}
+}
+
+func (h *PrescriptionHandler) Upload(c *gin.Context) {
+	userID := subjectUserID(c)
+
+	var req dto.PrescriptionUploadRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filePath string
+	file, err := c.FormFile("file")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if file != nil {
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		if !allowedPrescriptionExtensions[ext] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported file type"})
+			return
+		}
+		if file.Size > maxPrescriptionFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is too large"})
+			return
+		}
+
+		if err := os.MkdirAll(h.uploadDir, 0o755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		filePath = filepath.Join(h.uploadDir, fmt.Sprintf("%d_%d%s", userID, time.Now().UnixNano(), ext))
+		if err := c.SaveUploadedFile(file, filePath); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+	}
+
+	prescription, err := h.service.Upload(userID, req, filePath)
+	if err != nil {
+		if filePath != "" {
+			_ = os.Remove(filePath)
+		}
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, prescription)
+}
+
+func (h *PrescriptionHandler) ListByUser(c *gin.Context) {
+	userID := subjectUserID(c)
+
+	prescriptions, err := h.service.ListByUser(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prescriptions)
+}
+
+func (h *PrescriptionHandler) ListQueue(c *gin.Context) {
+	prescriptions, err := h.service.ListQueue(models.PrescriptionStatus(c.Query("status")))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prescriptions)
+}
+
+func (h *PrescriptionHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	prescription, err := h.service.GetByID(uint(id))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prescription)
+}
+
+// DownloadFile отдаёт фармацевту загруженный скан рецепта.
+func (h *PrescriptionHandler) DownloadFile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	prescription, err := h.service.GetByID(uint(id))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if prescription.FilePath == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "prescription has no file"})
+		return
+	}
+	if _, err := os.Stat(prescription.FilePath); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "prescription file not found"})
+		return
+	}
+
+	c.FileAttachment(prescription.FilePath, fmt.Sprintf("prescription_%d%s", prescription.ID, filepath.Ext(prescription.FilePath)))
+}
+
+func (h *PrescriptionHandler) Approve(c *gin.Context) {
+	h.review(c, h.service.Approve)
+}
+
+func (h *PrescriptionHandler) Reject(c *gin.Context) {
+	h.review(c, h.service.Reject)
+}
+
+func (h *PrescriptionHandler) review(c *gin.Context,
+	action func(id, reviewerID uint, req dto.PrescriptionReviewRequest) (*models.Prescription, error)) {
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.PrescriptionReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prescription, err := action(uint(id), currentUserID(c), req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prescription)
+}
+
+func (h *PrescriptionHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrPrescriptionNotFound),
+		errors.Is(err, errs.ErrUserNotFound),
+		errors.Is(err, errs.ErrMedicineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrPrescriptionReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidPrescription),
+		errors.Is(err, errs.ErrInvalidStatus),
+		errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
@@ -0,0 +1,133 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromocodeHandler struct {
+	service services.PromocodeService
+}
+
+func NewPromocodeHandler(service services.PromocodeService) *PromocodeHandler {
+	return &PromocodeHandler{service: service}
+}
+
+func (h *PromocodeHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	promocodes := r.Group("/promocodes", auth, RequireRole(models.RoleAdmin))
+	{
+		promocodes.GET("", h.List)
+		promocodes.POST("", h.Create)
+		promocodes.GET("/:id", h.GetByID)
+		promocodes.PATCH("/:id", h.Update)
+		promocodes.DELETE("/:id", h.Delete)
+		promocodes.GET("/:id/usages", h.ListUsages)
+	}
+}
+
+func (h *PromocodeHandler) Create(c *gin.Context) {
+	var req dto.PromocodeCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promocode, err := h.service.Create(req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, promocode)
+}
+
+func (h *PromocodeHandler) List(c *gin.Context) {
+	promocodes, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, promocodes)
+}
+
+func (h *PromocodeHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	promocode, err := h.service.GetByID(uint(id))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, promocode)
+}
+
+func (h *PromocodeHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.PromocodeUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promocode, err := h.service.Update(uint(id), req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, promocode)
+}
+
+func (h *PromocodeHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PromocodeHandler) ListUsages(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	usages, err := h.service.ListUsages(uint(id))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, usages)
+}
+
+func (h *PromocodeHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrPromocodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidPromocode), errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
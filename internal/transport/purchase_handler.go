@@ -0,0 +1,206 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PurchaseHandler struct {
+	service services.PurchaseService
+}
+
+func NewPurchaseHandler(service services.PurchaseService) *PurchaseHandler {
+	return &PurchaseHandler{service: service}
+}
+
+func (h *PurchaseHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	staff := RequireRole(models.RolePharmacist, models.RoleAdmin)
+	admin := RequireRole(models.RoleAdmin)
+
+	suppliers := r.Group("/suppliers", auth)
+	{
+		suppliers.GET("", staff, h.ListSuppliers)
+		suppliers.POST("", admin, h.CreateSupplier)
+		suppliers.PATCH("/:id", admin, h.UpdateSupplier)
+	}
+
+	orders := r.Group("/purchase-orders", auth)
+	{
+		orders.GET("", staff, h.ListOrders)
+		orders.GET("/:id", staff, h.GetOrder)
+		orders.POST("", admin, h.CreateOrder)
+		orders.PATCH("/:id", admin, h.UpdateOrder)
+		orders.POST("/:id/send", admin, h.SendOrder)
+		orders.POST("/:id/receive", staff, h.ReceiveOrder)
+	}
+}
+
+func (h *PurchaseHandler) CreateSupplier(c *gin.Context) {
+	var req dto.SupplierCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	supplier, err := h.service.CreateSupplier(req)
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, supplier)
+}
+
+func (h *PurchaseHandler) UpdateSupplier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.SupplierUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	supplier, err := h.service.UpdateSupplier(uint(id), req)
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, supplier)
+}
+
+func (h *PurchaseHandler) ListSuppliers(c *gin.Context) {
+	suppliers, err := h.service.ListSuppliers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, suppliers)
+}
+
+func (h *PurchaseHandler) CreateOrder(c *gin.Context) {
+	var req dto.PurchaseOrderCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.service.CreateOrder(currentUserID(c), req)
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+func (h *PurchaseHandler) UpdateOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.PurchaseOrderUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.service.UpdateOrder(uint(id), req)
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *PurchaseHandler) SendOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	order, err := h.service.SendOrder(uint(id))
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *PurchaseHandler) ReceiveOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.PurchaseReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.service.ReceiveOrder(uint(id), currentUserID(c), req)
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *PurchaseHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	order, err := h.service.GetOrder(uint(id))
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *PurchaseHandler) ListOrders(c *gin.Context) {
+	var query dto.PurchaseOrderQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := h.service.ListOrders(query)
+	if err != nil {
+		writePurchaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+func writePurchaseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrSupplierNotFound), errors.Is(err, errs.ErrPurchaseOrderNotFound),
+		errors.Is(err, errs.ErrWarehouseNotFound), errors.Is(err, errs.ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrPurchaseOrderNotDraft), errors.Is(err, errs.ErrPurchaseOrderNotSent):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidReceipt), errors.Is(err, errs.ErrBatchExpired):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
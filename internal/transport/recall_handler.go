@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecallHandler struct {
+	service services.RecallService
+}
+
+func NewRecallHandler(service services.RecallService) *RecallHandler {
+	return &RecallHandler{service: service}
+}
+
+func (h *RecallHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	recalls := r.Group("/recalls", auth)
+	{
+		recalls.POST("", RequireRole(models.RoleAdmin), h.Create)
+		recalls.GET("", RequireRole(models.RolePharmacist, models.RoleAdmin), h.List)
+		recalls.GET("/:id", RequireRole(models.RolePharmacist, models.RoleAdmin), h.GetByID)
+	}
+}
+
+func (h *RecallHandler) Create(c *gin.Context) {
+	var req dto.RecallCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recall, err := h.service.Create(currentUserID(c), req)
+	if err != nil {
+		writeRecallError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, recall)
+}
+
+func (h *RecallHandler) List(c *gin.Context) {
+	recalls, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, recalls)
+}
+
+func (h *RecallHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	recall, err := h.service.GetByID(uint(id))
+	if err != nil {
+		writeRecallError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recall)
+}
+
+func writeRecallError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrRecallNotFound), errors.Is(err, errs.ErrMedicineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrRecallLotsNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
@@ -13,18 +13,67 @@ func RegisterRoutes(router *gin.Engine,
 	orderService services.OrderService,
 	categoryService services.CategoryService,
 	subcategoryService services.SubcategoryService,
+	medicineService services.MedicineService,
+	paymentService services.PaymentService,
+	promocodeService services.PromocodeService,
+	prescriptionService services.PrescriptionService,
+	prescriptionUploadDir string,
+	authService services.AuthService,
+	interactionService services.InteractionService,
+	stockService services.StockService,
+	recallService services.RecallService,
+	warehouseService services.WarehouseService,
+	purchaseService services.PurchaseService,
+	inventoryService services.InventoryService,
+	stocktakeService services.StocktakeService,
+	pickupPointService services.PickupPointService,
+	deliveryService services.DeliveryService,
+	shipmentService services.ShipmentService,
 	logger *slog.Logger) {
 
+	auth := RequireAuth(authService)
+	orderAccess := RequireOrderAccess(orderService)
+
 	userHandler := NewUserHandler(userService)
 	categoryHandler := NewCategoryHandler(categoryService)
 	subcategoryHandler := NewSubcategoryHandler(subcategoryService)
+	medicineHandler := NewMedicineHandler(medicineService)
 	cartHandler := NewCartHandler(logger, cartService)
 	orderHandler := NewOrderHandler(orderService, userService, cartService)
+	paymentHandler := NewPaymentHandler(paymentService)
+	promocodeHandler := NewPromocodeHandler(promocodeService)
+	prescriptionHandler := NewPrescriptionHandler(prescriptionService, prescriptionUploadDir)
+	authHandler := NewAuthHandler(authService)
+	interactionHandler := NewInteractionHandler(interactionService)
+	stockHandler := NewStockHandler(stockService)
+	recallHandler := NewRecallHandler(recallService)
+	warehouseHandler := NewWarehouseHandler(warehouseService)
+	purchaseHandler := NewPurchaseHandler(purchaseService)
+	inventoryHandler := NewInventoryHandler(inventoryService)
+	stocktakeHandler := NewStocktakeHandler(stocktakeService)
+	pickupPointHandler := NewPickupPointHandler(pickupPointService)
+	deliveryHandler := NewDeliveryHandler(deliveryService)
+	shipmentHandler := NewShipmentHandler(shipmentService)
 
-	userHandler.RegisterRoutes(router)
-	categoryHandler.RegisterRoutes(router)
-	subcategoryHandler.RegisterRoutes(router)
-	cartHandler.RegisterRoutes(router)
-	orderHandler.RegisterRoutes(router)
+	authHandler.RegisterRoutes(router)
+	userHandler.RegisterRoutes(router, auth)
+	categoryHandler.RegisterRoutes(router, auth)
+	subcategoryHandler.RegisterRoutes(router, auth)
+	medicineHandler.RegisterRoutes(router, auth)
+	cartHandler.RegisterRoutes(router, auth)
+	orderHandler.RegisterRoutes(router, auth)
+	paymentHandler.RegisterRoutes(router, auth, orderAccess)
+	promocodeHandler.RegisterRoutes(router, auth)
+	prescriptionHandler.RegisterRoutes(router, auth)
+	interactionHandler.RegisterRoutes(router, auth)
+	stockHandler.RegisterRoutes(router, auth)
+	recallHandler.RegisterRoutes(router, auth)
+	warehouseHandler.RegisterRoutes(router, auth)
+	purchaseHandler.RegisterRoutes(router, auth)
+	inventoryHandler.RegisterRoutes(router, auth)
+	stocktakeHandler.RegisterRoutes(router, auth)
+	pickupPointHandler.RegisterRoutes(router, auth)
+	deliveryHandler.RegisterRoutes(router, auth)
+	shipmentHandler.RegisterRoutes(router, auth, orderAccess)
 
 }
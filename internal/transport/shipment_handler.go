@@ -0,0 +1,186 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShipmentHandler struct {
+	service services.ShipmentService
+}
+
+func NewShipmentHandler(service services.ShipmentService) *ShipmentHandler {
+	return &ShipmentHandler{service: service}
+}
+
+func (h *ShipmentHandler) RegisterRoutes(r *gin.Engine, auth, orderAccess gin.HandlerFunc) {
+	staff := RequireRole(models.RolePharmacist, models.RoleAdmin)
+
+	order := r.Group("/orders/:id", auth, orderAccess)
+	{
+		order.GET("/shipment", h.GetByOrder)
+		order.POST("/shipment", staff, h.Create)
+	}
+
+	shipments := r.Group("/shipments", auth)
+	{
+		shipments.GET("/:id", staff, h.Get)
+		shipments.PATCH("/:id", staff, h.Update)
+		shipments.POST("/:id/dispatch", staff, h.Dispatch)
+		shipments.POST("/:id/attempts", RequireRole(models.RoleCourier, models.RolePharmacist, models.RoleAdmin), h.RecordAttempt)
+	}
+
+	courier := r.Group("/courier", auth, RequireRole(models.RoleCourier))
+	{
+		courier.GET("/shipments", h.CourierShipments)
+	}
+}
+
+func (h *ShipmentHandler) Create(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.ShipmentCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shipment, err := h.service.Create(uint(orderID), currentUserID(c), req)
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, shipment)
+}
+
+func (h *ShipmentHandler) GetByOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	shipment, err := h.service.GetByOrder(uint(orderID))
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	shipment, err := h.service.Get(uint(id))
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.ShipmentUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shipment, err := h.service.Update(uint(id), req)
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) Dispatch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	shipment, err := h.service.Dispatch(uint(id), currentUserID(c))
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) RecordAttempt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.DeliveryAttemptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shipment, err := h.service.RecordAttempt(uint(id), currentUserID(c), currentUserRole(c), req)
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) CourierShipments(c *gin.Context) {
+	var query dto.CourierShipmentQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shipments, err := h.service.CourierShipments(currentUserID(c), query)
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shipments)
+}
+
+func writeShipmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrShipmentNotFound), errors.Is(err, errs.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrShipmentExists), errors.Is(err, errs.ErrShipmentNotAllowed),
+		errors.Is(err, errs.ErrInvalidShipmentState), errors.Is(err, errs.ErrInvalidStatusTransition),
+		errors.Is(err, errs.ErrOrderRecalled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidCourier), errors.Is(err, errs.ErrCourierRequired):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
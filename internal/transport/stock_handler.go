@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockHandler struct {
+	service services.StockService
+}
+
+func NewStockHandler(service services.StockService) *StockHandler {
+	return &StockHandler{service: service}
+}
+
+func (h *StockHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	medicines := r.Group("/medicines", auth)
+	{
+		medicines.GET("/:id/batches", RequireRole(models.RolePharmacist, models.RoleAdmin), h.ListBatches)
+		medicines.POST("/:id/variants/:variant_id/batches", RequireRole(models.RoleAdmin), h.ReceiveBatch)
+		medicines.GET("/:id/stock-movements", RequireRole(models.RolePharmacist, models.RoleAdmin), h.ListMovements)
+		medicines.POST("/:id/batches/:batch_id/movements", RequireRole(models.RoleAdmin), h.RecordMovement)
+	}
+}
+
+func (h *StockHandler) ReceiveBatch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+	variantID, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct variant id"})
+		return
+	}
+
+	var req dto.StockBatchCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	batch, err := h.service.ReceiveBatch(uint(id), uint(variantID), currentUserID(c), req)
+	if err != nil {
+		writeStockError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, batch)
+}
+
+func (h *StockHandler) ListBatches(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	batches, err := h.service.ListBatches(uint(id))
+	if err != nil {
+		writeStockError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, batches)
+}
+
+func (h *StockHandler) RecordMovement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+	batchID, err := strconv.ParseUint(c.Param("batch_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct batch id"})
+		return
+	}
+
+	var req dto.StockMovementCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movement, err := h.service.RecordMovement(uint(id), uint(batchID), currentUserID(c), req)
+	if err != nil {
+		writeStockError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, movement)
+}
+
+func (h *StockHandler) ListMovements(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var query dto.StockMovementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movements, err := h.service.ListMovements(uint(id), query)
+	if err != nil {
+		writeStockError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, movements)
+}
+
+func writeStockError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrMedicineNotFound), errors.Is(err, errs.ErrVariantNotFound),
+		errors.Is(err, errs.ErrBatchNotFound), errors.Is(err, errs.ErrWarehouseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID), errors.Is(err, errs.ErrBatchExpired),
+		errors.Is(err, errs.ErrInvalidStockMovement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
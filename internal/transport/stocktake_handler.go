@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StocktakeHandler struct {
+	service services.StocktakeService
+}
+
+func NewStocktakeHandler(service services.StocktakeService) *StocktakeHandler {
+	return &StocktakeHandler{service: service}
+}
+
+func (h *StocktakeHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	staff := RequireRole(models.RolePharmacist, models.RoleAdmin)
+	admin := RequireRole(models.RoleAdmin)
+
+	stocktakes := r.Group("/stocktakes", auth)
+	{
+		stocktakes.GET("", staff, h.List)
+		stocktakes.GET("/:id", staff, h.Get)
+		stocktakes.GET("/:id/variance", staff, h.Variance)
+		stocktakes.POST("", staff, h.Create)
+		stocktakes.POST("/:id/counts", staff, h.Count)
+		stocktakes.POST("/:id/apply", admin, h.Apply)
+		stocktakes.POST("/:id/cancel", admin, h.Cancel)
+	}
+}
+
+func (h *StocktakeHandler) Create(c *gin.Context) {
+	var req dto.StocktakeCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stocktake, err := h.service.Create(currentUserID(c), req)
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, stocktake)
+}
+
+func (h *StocktakeHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	stocktake, err := h.service.Get(uint(id))
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stocktake)
+}
+
+func (h *StocktakeHandler) List(c *gin.Context) {
+	var query dto.StocktakeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stocktakes, err := h.service.List(query)
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stocktakes)
+}
+
+func (h *StocktakeHandler) Count(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.StocktakeCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stocktake, err := h.service.Count(uint(id), currentUserID(c), req)
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stocktake)
+}
+
+func (h *StocktakeHandler) Variance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var query dto.StocktakeVarianceQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Variance(uint(id), query)
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *StocktakeHandler) Apply(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	stocktake, err := h.service.Apply(uint(id), currentUserID(c))
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stocktake)
+}
+
+func (h *StocktakeHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	stocktake, err := h.service.Cancel(uint(id))
+	if err != nil {
+		writeStocktakeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stocktake)
+}
+
+func writeStocktakeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrStocktakeNotFound), errors.Is(err, errs.ErrWarehouseNotFound),
+		errors.Is(err, errs.ErrBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrStocktakeNotOpen), errors.Is(err, errs.ErrStocktakeInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidStocktakeCount), errors.Is(err, errs.ErrEmptyStocktake),
+		errors.Is(err, errs.ErrVariantRequired):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -19,12 +20,12 @@ func NewSubcategoryHandler(service services.SubcategoryService) *SubcategoryHand
 	return &SubcategoryHandler{service: service}
 }
 
-func (h *SubcategoryHandler) RegisterRoutes(r *gin.Engine) {
+func (h *SubcategoryHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
 	categories := r.Group("/categories")
 
 	{
 		categories.GET("/:id/subcategories", h.GetByCategory)
-		categories.POST("/:id/subcategories", h.Create)
+		categories.POST("/:id/subcategories", auth, RequireRole(models.RoleAdmin), h.Create)
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/models"
 	"team-pharmacy/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -18,15 +19,20 @@ func NewUserHandler(service services.UserService) *UserHandler {
 	return &UserHandler{service: service}
 }
 
-func (h *UserHandler) RegisterRoutes(r *gin.Engine) {
-	users := r.Group("/users")
+func (h *UserHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	users := r.Group("/users", auth, RequireRole(models.RoleAdmin))
 
 	{
-		users.GET("/:id", h.GetByID)
 		users.POST("", h.Create)
-		users.PATCH("/:id", h.Update)
-		users.DELETE("/:id", h.Delete)
 		users.GET("", h.List)
+		users.PATCH("/:id/role", h.UpdateRole)
+	}
+
+	user := r.Group("/users/:id", auth, RequireSelf())
+	{
+		user.GET("", h.GetByID)
+		user.PATCH("", h.Update)
+		user.DELETE("", h.Delete)
 	}
 
 }
@@ -48,15 +54,9 @@ func (h *UserHandler) Create(c *gin.Context) {
 }
 
 func (h *UserHandler) GetByID(c *gin.Context) {
-	idParam := c.Param("id")
+	id := subjectUserID(c)
 
-	id, err := strconv.ParseUint(idParam, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
-	}
-
-	user, err := h.service.GetUserByID(uint(id))
+	user, err := h.service.GetUserByID(id)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -69,13 +69,7 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 }
 
 func (h *UserHandler) Update(c *gin.Context) {
-	idParam := c.Param("id")
-
-	id, err := strconv.ParseUint(idParam, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
-	}
+	id := subjectUserID(c)
 
 	req := dto.UpdateUserRequest{}
 
@@ -84,7 +78,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.UpdateUser(uint(id), req)
+	user, err := h.service.UpdateUser(id, req)
 	if err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -98,15 +92,9 @@ func (h *UserHandler) Update(c *gin.Context) {
 }
 
 func (h *UserHandler) Delete(c *gin.Context) {
-	idParam := c.Param("id")
+	id := subjectUserID(c)
 
-	id, err := strconv.ParseUint(idParam, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
-	}
-
-	if err := h.service.DeleteUser(uint(id)); err != nil {
+	if err := h.service.DeleteUser(id); err != nil {
 		if errors.Is(err, services.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
@@ -126,3 +114,28 @@ func (h *UserHandler) List(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, list)
 }
+
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req dto.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.UpdateRole(uint(id), req)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,162 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"team-pharmacy/internal/dto"
+	"team-pharmacy/internal/errs"
+	"team-pharmacy/internal/models"
+	"team-pharmacy/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseHandler struct {
+	service services.WarehouseService
+}
+
+func NewWarehouseHandler(service services.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{service: service}
+}
+
+func (h *WarehouseHandler) RegisterRoutes(r *gin.Engine, auth gin.HandlerFunc) {
+	r.GET("/warehouses", h.List)
+	r.GET("/medicines/:id/availability", h.MedicineAvailability)
+
+	staff := RequireRole(models.RolePharmacist, models.RoleAdmin)
+	admin := RequireRole(models.RoleAdmin)
+
+	warehouses := r.Group("/warehouses", auth)
+	{
+		warehouses.POST("", admin, h.Create)
+		warehouses.PATCH("/:id", admin, h.Update)
+		warehouses.GET("/:id/stock", staff, h.Stock)
+		warehouses.GET("/transfers", staff, h.ListTransfers)
+		warehouses.POST("/transfers", admin, h.Transfer)
+	}
+}
+
+func (h *WarehouseHandler) Create(c *gin.Context) {
+	var req dto.WarehouseCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	warehouse, err := h.service.Create(req)
+	if err != nil {
+		writeWarehouseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, warehouse)
+}
+
+func (h *WarehouseHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	var req dto.WarehouseUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	warehouse, err := h.service.Update(uint(id), req)
+	if err != nil {
+		writeWarehouseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, warehouse)
+}
+
+func (h *WarehouseHandler) List(c *gin.Context) {
+	warehouses, err := h.service.List(c.Query("include_inactive") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	c.JSON(http.StatusOK, warehouses)
+}
+
+func (h *WarehouseHandler) Stock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	stock, err := h.service.Stock(uint(id))
+	if err != nil {
+		writeWarehouseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stock)
+}
+
+func (h *WarehouseHandler) MedicineAvailability(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct id"})
+		return
+	}
+
+	availability, err := h.service.MedicineAvailability(uint(id))
+	if err != nil {
+		writeWarehouseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *WarehouseHandler) Transfer(c *gin.Context) {
+	var req dto.StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.service.Transfer(currentUserID(c), req)
+	if err != nil {
+		writeWarehouseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *WarehouseHandler) ListTransfers(c *gin.Context) {
+	var warehouseID *uint
+	if raw := c.Query("warehouse_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Is not Correct warehouse id"})
+			return
+		}
+		value := uint(id)
+		warehouseID = &value
+	}
+
+	transfers, err := h.service.ListTransfers(warehouseID)
+	if err != nil {
+		writeWarehouseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+func writeWarehouseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errs.ErrWarehouseNotFound), errors.Is(err, errs.ErrMedicineNotFound),
+		errors.Is(err, errs.ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInvalidTransfer):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+	}
+}